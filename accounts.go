@@ -0,0 +1,79 @@
+package poloniex
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+type Account string
+
+const (
+	AccountExchange Account = "exchange"
+	AccountMargin   Account = "margin"
+	AccountLending  Account = "lending"
+)
+
+type CompleteBalance struct {
+	Available decimal.Decimal `json:"available"`
+	OnOrders  decimal.Decimal `json:"onOrders"`
+	BtcValue  decimal.Decimal `json:"btcValue"`
+}
+
+// CompleteBalances returns balances of the exchange account, or of all accounts when allAccounts is set
+func (client *Client) CompleteBalances(allAccounts bool) (balances map[string]CompleteBalance, err error) {
+	return client.CompleteBalancesCtx(context.Background(), allAccounts)
+}
+
+func (client *Client) CompleteBalancesCtx(ctx context.Context, allAccounts bool) (balances map[string]CompleteBalance, err error) {
+	params := Params{}
+	if allAccounts {
+		params["account"] = "all"
+	}
+
+	err = client.tradingApiRequest(ctx, &balances, "returnCompleteBalances", params)
+	return
+}
+
+// AvailableAccountBalances returns available balances of the account, or of every account when account is empty
+func (client *Client) AvailableAccountBalances(account Account) (balances map[Account]map[string]decimal.Decimal, err error) {
+	return client.AvailableAccountBalancesCtx(context.Background(), account)
+}
+
+func (client *Client) AvailableAccountBalancesCtx(ctx context.Context, account Account) (balances map[Account]map[string]decimal.Decimal, err error) {
+	params := Params{}
+	if account != "" {
+		params["account"] = string(account)
+	}
+
+	err = client.tradingApiRequest(ctx, &balances, "returnAvailableAccountBalances", params)
+	return
+}
+
+func (client *Client) TransferBalance(currency string, amount decimal.Decimal, fromAccount, toAccount Account) (message string, err error) {
+	return client.TransferBalanceCtx(context.Background(), currency, amount, fromAccount, toAccount)
+}
+
+func (client *Client) TransferBalanceCtx(ctx context.Context, currency string, amount decimal.Decimal, fromAccount, toAccount Account) (message string, err error) {
+	result := struct {
+		Success convertibleBool
+		Message string
+	}{}
+
+	err = client.tradingApiRequest(ctx, &result, "transferBalance", Params{
+		"currency":    currency,
+		"amount":      amount.String(),
+		"fromAccount": string(fromAccount),
+		"toAccount":   string(toAccount),
+	})
+	if err != nil {
+		return result.Message, err
+	}
+
+	if !result.Success {
+		err = fmt.Errorf("transferBalance of %s from %s to %s is not successful: %s", currency, fromAccount, toAccount, result.Message)
+	}
+
+	return result.Message, err
+}
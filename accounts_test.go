@@ -0,0 +1,107 @@
+package poloniex
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClient_CompleteBalances(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var account string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				account = r.PostForm.Get("account")
+				fmt.Fprint(w, `{"LTC":{"available":"5.015","onOrders":"1.0025","btcValue":"0.078"},"NXT":{"available":"5812.2","onOrders":"0","btcValue":"0.2"}}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return complete balances of exchange account", func() {
+			balances, err := client.CompleteBalances(false)
+			So(err, ShouldBeNil)
+
+			So(account, ShouldEqual, "")
+			So(len(balances), ShouldEqual, 2)
+			So(balances["LTC"].OnOrders.String(), ShouldEqual, "1.0025")
+			So(balances["LTC"].BtcValue.String(), ShouldEqual, "0.078")
+		})
+
+		Convey("Should request complete balances of all accounts", func() {
+			_, err := client.CompleteBalances(true)
+			So(err, ShouldBeNil)
+			So(account, ShouldEqual, "all")
+		})
+	})
+}
+
+func TestClient_AvailableAccountBalances(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"exchange":{"BTC":"1.19042859","BTS":"115.0"},"margin":{"BTC":"3.90015637"},"lending":{"DASH":"0.01174765"}}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return available balances by account", func() {
+			balances, err := client.AvailableAccountBalances("")
+			So(err, ShouldBeNil)
+
+			So(len(balances), ShouldEqual, 3)
+			So(balances[AccountMargin]["BTC"].String(), ShouldEqual, "3.90015637")
+			So(balances[AccountLending]["DASH"].String(), ShouldEqual, "0.01174765")
+		})
+	})
+}
+
+func TestClient_TransferBalance(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var form map[string]string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				form = map[string]string{
+					"fromAccount": r.PostForm.Get("fromAccount"),
+					"toAccount":   r.PostForm.Get("toAccount"),
+				}
+				fmt.Fprint(w, `{"success":1,"message":"Transferred 2 BTC from exchange to margin account."}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should transfer balance", func() {
+			message, err := client.TransferBalance("BTC", decimal.New(2, 0), AccountExchange, AccountMargin)
+			So(err, ShouldBeNil)
+
+			So(message, ShouldEqual, "Transferred 2 BTC from exchange to margin account.")
+			So(form["fromAccount"], ShouldEqual, "exchange")
+			So(form["toAccount"], ShouldEqual, "margin")
+		})
+
+		Convey("Should return error on unsuccessful response", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"success":0,"message":"Transfer failed."}`)
+			}
+
+			_, err := client.TransferBalance("BTC", decimal.New(2, 0), AccountExchange, AccountMargin)
+			So(err, ShouldBeError)
+		})
+	})
+}
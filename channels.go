@@ -0,0 +1,185 @@
+package poloniex
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	wampTopicTicker   = "ticker"
+	wampTopicTrollbox = "trollbox"
+	wampTopicFooter   = "footer"
+
+	tickerArgsCount   = 10
+	trollboxArgsCount = 4
+)
+
+// TickerUpdate carries the ticker of a market, Market.Id is not published and left zero
+type TickerUpdate struct {
+	CurrencyPair string
+	Market
+}
+
+type TrollboxMessage struct {
+	Number     uint64
+	Username   string
+	Message    string
+	Reputation int64
+}
+
+// FooterUpdate carries exchange statistics shown in the site footer. Volume values include currency units, like "31457.48 BTC".
+type FooterUpdate struct {
+	ServerTime  string
+	UsersOnline uint64
+	Volume      map[string]string
+}
+
+// SubscribeToTicker sends ticker updates of every market to updateChan
+func (wampClient *WampClient) SubscribeToTicker(updateChan chan TickerUpdate, errChan chan error) error {
+	return wampClient.subscribe(wampTopicTicker, func(args []interface{}, kwargs map[string]interface{}) {
+		update, err := newTickerUpdate(args)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		updateChan <- update
+	})
+}
+
+func (wampClient *WampClient) UnsubscribeFromTicker() error {
+	return wampClient.UnsubscribeFromPair(wampTopicTicker)
+}
+
+func (wampClient *WampClient) SubscribeToTrollbox(messageChan chan TrollboxMessage, errChan chan error) error {
+	return wampClient.subscribe(wampTopicTrollbox, func(args []interface{}, kwargs map[string]interface{}) {
+		message, err := newTrollboxMessage(args)
+		if err != nil {
+			errChan <- err
+			return
+		}
+
+		messageChan <- message
+	})
+}
+
+func (wampClient *WampClient) UnsubscribeFromTrollbox() error {
+	return wampClient.UnsubscribeFromPair(wampTopicTrollbox)
+}
+
+func (wampClient *WampClient) SubscribeToFooter(updateChan chan FooterUpdate, errChan chan error) error {
+	return wampClient.subscribe(wampTopicFooter, func(args []interface{}, kwargs map[string]interface{}) {
+		for _, arg := range args {
+			update, err := newFooterUpdate(arg)
+			if err != nil {
+				errChan <- err
+				continue
+			}
+
+			updateChan <- update
+		}
+	})
+}
+
+func (wampClient *WampClient) UnsubscribeFromFooter() error {
+	return wampClient.UnsubscribeFromPair(wampTopicFooter)
+}
+
+// newTickerUpdate parses [pair, last, lowestAsk, highestBid, percentChange, baseVolume, quoteVolume, isFrozen, high24hr, low24hr]
+func newTickerUpdate(args []interface{}) (update TickerUpdate, err error) {
+	if len(args) < tickerArgsCount {
+		return update, fmt.Errorf("ticker update has %d arguments, expected %d", len(args), tickerArgsCount)
+	}
+
+	pair, ok := args[0].(string)
+	if !ok {
+		return update, fmt.Errorf("ticker update pair (%#v) type is %T, expected string", args[0], args[0])
+	}
+	update.CurrencyPair = pair
+
+	fields := []struct {
+		name  string
+		value *decimal.Decimal
+		arg   interface{}
+	}{
+		{"last", &update.Last, args[1]},
+		{"lowestAsk", &update.LowestAsk, args[2]},
+		{"highestBid", &update.HighestBid, args[3]},
+		{"percentChange", &update.PercentChange, args[4]},
+		{"baseVolume", &update.BaseVolume, args[5]},
+		{"quoteVolume", &update.QuoteVolume, args[6]},
+		{"high24hr", &update.High24hr, args[8]},
+		{"low24hr", &update.Low24hr, args[9]},
+	}
+
+	for _, field := range fields {
+		if *field.value, err = decimalArg(field.arg); err != nil {
+			return update, fmt.Errorf("ticker update of %s, %s: %s", pair, field.name, err)
+		}
+	}
+
+	if err = update.IsFrozen.UnmarshalJSON([]byte(fmt.Sprint(args[7]))); err != nil {
+		return update, fmt.Errorf("ticker update of %s, isFrozen: %s", pair, err)
+	}
+
+	return update, nil
+}
+
+// newTrollboxMessage parses ["trollboxMessage", number, username, message, reputation], reputation may be missing
+func newTrollboxMessage(args []interface{}) (message TrollboxMessage, err error) {
+	if len(args) < trollboxArgsCount {
+		return message, fmt.Errorf("trollbox message has %d arguments, expected at least %d", len(args), trollboxArgsCount)
+	}
+
+	number, ok := args[1].(float64)
+	if !ok {
+		return message, fmt.Errorf("trollbox message number (%#v) type is %T, expected float64", args[1], args[1])
+	}
+	message.Number = uint64(number)
+
+	if message.Username, ok = args[2].(string); !ok {
+		return message, fmt.Errorf("trollbox message username (%#v) type is %T, expected string", args[2], args[2])
+	}
+
+	if message.Message, ok = args[3].(string); !ok {
+		return message, fmt.Errorf("trollbox message text (%#v) type is %T, expected string", args[3], args[3])
+	}
+
+	if len(args) > trollboxArgsCount {
+		reputation, ok := args[4].(float64)
+		if !ok {
+			return message, fmt.Errorf("trollbox message reputation (%#v) type is %T, expected float64", args[4], args[4])
+		}
+		message.Reputation = int64(reputation)
+	}
+
+	return message, nil
+}
+
+func newFooterUpdate(arg interface{}) (update FooterUpdate, err error) {
+	config := &mapstructure.DecoderConfig{WeaklyTypedInput: true, Result: &update}
+	decoder, err := mapstructure.NewDecoder(config)
+	if err != nil {
+		return update, err
+	}
+
+	if err = decoder.Decode(arg); err != nil {
+		return update, fmt.Errorf("footer update: %s", err)
+	}
+
+	return update, nil
+}
+
+// decimalArg converts WAMP arguments, which carry numbers either as strings or as floats
+func decimalArg(arg interface{}) (decimal.Decimal, error) {
+	switch value := arg.(type) {
+	case string:
+		return decimal.NewFromString(value)
+	case float64:
+		return decimal.NewFromFloat(value), nil
+	}
+
+	return decimal.Zero, fmt.Errorf("value (%#v) type is %T, expected string or float64", arg, arg)
+}
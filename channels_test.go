@@ -0,0 +1,141 @@
+package poloniex
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func Test_newTickerUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []interface{}
+		wantUpdate TickerUpdate
+		wantErr    bool
+	}{
+		{
+			name: "valid update",
+			args: []interface{}{"BTC_ETH", "0.1", "0.2", "0.05", "-0.5", "100", "1000", float64(1), "0.3", 0.04},
+			wantUpdate: TickerUpdate{
+				CurrencyPair: "BTC_ETH",
+				Market: Market{
+					Last:          decimal.New(1, -1),
+					LowestAsk:     decimal.New(2, -1),
+					HighestBid:    decimal.New(5, -2),
+					PercentChange: decimal.New(-5, -1),
+					BaseVolume:    decimal.New(100, 0),
+					QuoteVolume:   decimal.New(1000, 0),
+					IsFrozen:      true,
+					High24hr:      decimal.New(3, -1),
+					Low24hr:       decimal.NewFromFloat(0.04),
+				},
+			},
+		},
+		{
+			name:    "too few arguments",
+			args:    []interface{}{"BTC_ETH", "0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid pair",
+			args:    []interface{}{1.0, "0.1", "0.2", "0.05", "-0.5", "100", "1000", float64(0), "0.3", "0.04"},
+			wantErr: true,
+		},
+		{
+			name:       "invalid rate",
+			args:       []interface{}{"BTC_ETH", "invalid", "0.2", "0.05", "-0.5", "100", "1000", float64(0), "0.3", "0.04"},
+			wantUpdate: TickerUpdate{CurrencyPair: "BTC_ETH"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUpdate, err := newTickerUpdate(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newTickerUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotUpdate, tt.wantUpdate) {
+				t.Errorf("newTickerUpdate() = %v, want %v", gotUpdate, tt.wantUpdate)
+			}
+		})
+	}
+}
+
+func Test_newTrollboxMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []interface{}
+		wantMessage TrollboxMessage
+		wantErr     bool
+	}{
+		{
+			name:        "valid message",
+			args:        []interface{}{"trollboxMessage", float64(2094211), "boxOfTroll", "Trololol", float64(4)},
+			wantMessage: TrollboxMessage{Number: 2094211, Username: "boxOfTroll", Message: "Trololol", Reputation: 4},
+		},
+		{
+			name:        "without reputation",
+			args:        []interface{}{"trollboxMessage", float64(2094211), "boxOfTroll", "Trololol"},
+			wantMessage: TrollboxMessage{Number: 2094211, Username: "boxOfTroll", Message: "Trololol"},
+		},
+		{
+			name:    "invalid number",
+			args:    []interface{}{"trollboxMessage", "2094211", "boxOfTroll", "Trololol"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotMessage, err := newTrollboxMessage(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newTrollboxMessage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotMessage, tt.wantMessage) {
+				t.Errorf("newTrollboxMessage() = %v, want %v", gotMessage, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func Test_newFooterUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		arg        interface{}
+		wantUpdate FooterUpdate
+		wantErr    bool
+	}{
+		{
+			name: "valid update",
+			arg: map[string]interface{}{
+				"serverTime":  "2017-09-21 20:35",
+				"usersOnline": float64(23470),
+				"volume":      map[string]interface{}{"BTC": "31457.482 BTC"},
+			},
+			wantUpdate: FooterUpdate{
+				ServerTime:  "2017-09-21 20:35",
+				UsersOnline: 23470,
+				Volume:      map[string]string{"BTC": "31457.482 BTC"},
+			},
+		},
+		{
+			name:    "invalid update",
+			arg:     "footer",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUpdate, err := newFooterUpdate(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newFooterUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotUpdate, tt.wantUpdate) {
+				t.Errorf("newFooterUpdate() = %v, want %v", gotUpdate, tt.wantUpdate)
+			}
+		})
+	}
+}
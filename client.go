@@ -1,17 +1,24 @@
 package poloniex
 
 import (
-	"time"
-
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
-	"gopkg.in/resty.v0"
 )
 
 const (
 	defaultTimeout       = 130 * time.Second
 	maxRequestsPerSecond = 6
+
+	defaultBaseURL = "https://poloniex.com"
+	publicApiPath  = "/public"
+	tradingApiPath = "/tradingApi"
+
+	publicApiEndpoint  = defaultBaseURL + publicApiPath
+	tradingApiEndpoint = defaultBaseURL + tradingApiPath
 )
 
 type Key struct {
@@ -20,18 +27,78 @@ type Key struct {
 }
 
 type Client struct {
-	keyPool keyPool
-	resty   *resty.Client
-	limiter *rate.Limiter
+	keyPool         keyPool
+	httpClient      *http.Client
+	publicEndpoint  string
+	tradingEndpoint string
+	limiter         *rate.Limiter
+	retryPolicy     RetryPolicy
+	nonceSource     NonceSource
+
+	middleware      []Middleware
+	middlewareMutex sync.RWMutex
+}
+
+// ClientOption configures a Client in NewClient
+type ClientOption func(client *Client)
+
+// WithHTTPClient makes the client send requests through a copy of httpClient
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(client *Client) {
+		copied := *httpClient
+		client.httpClient = &copied
+	}
+}
+
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(client *Client) {
+		client.httpClient.Transport = transport
+	}
+}
+
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *Client) {
+		client.httpClient.Timeout = timeout
+	}
+}
+
+func WithPublicEndpoint(endpoint string) ClientOption {
+	return func(client *Client) {
+		client.publicEndpoint = endpoint
+	}
 }
 
-func NewClient(keys []Key) *Client {
+func WithTradingEndpoint(endpoint string) ClientOption {
+	return func(client *Client) {
+		client.tradingEndpoint = endpoint
+	}
+}
+
+// WithBaseURL points both APIs at a mirror or a mock serving the exchange paths, like "http://localhost:8080"
+func WithBaseURL(baseURL string) ClientOption {
+	return func(client *Client) {
+		base := strings.TrimSuffix(baseURL, "/")
+		client.publicEndpoint = base + publicApiPath
+		client.tradingEndpoint = base + tradingApiPath
+	}
+}
+
+// NewClient creates a client with its own HTTP stack, options are applied in order
+func NewClient(keys []Key, options ...ClientOption) *Client {
 	client := Client{
 		keyPool: keyPool{
 			keys: make(chan *Key, len(keys)),
 		},
-		resty:   resty.DefaultClient.SetTimeout(defaultTimeout),
-		limiter: rate.NewLimiter(maxRequestsPerSecond, 1),
+		httpClient:      &http.Client{Timeout: defaultTimeout},
+		publicEndpoint:  publicApiEndpoint,
+		tradingEndpoint: tradingApiEndpoint,
+		limiter:         rate.NewLimiter(maxRequestsPerSecond, 1),
+		retryPolicy:     DefaultRetryPolicy(),
+		nonceSource:     NewMonotonicNonceSource(),
+	}
+
+	for _, option := range options {
+		option(&client)
 	}
 
 	for i := range keys {
@@ -42,11 +109,11 @@ func NewClient(keys []Key) *Client {
 }
 
 func (client *Client) SetTimeout(timeout time.Duration) {
-	client.resty.SetTimeout(timeout)
+	client.httpClient.Timeout = timeout
 }
 
-func (client *Client) SetTransport(transport *http.Transport) {
-	client.resty.SetTransport(transport)
+func (client *Client) SetTransport(transport http.RoundTripper) {
+	client.httpClient.Transport = transport
 }
 
 func (client *Client) SetRequestRateLimit(limit rate.Limit) {
@@ -54,3 +121,11 @@ func (client *Client) SetRequestRateLimit(limit rate.Limit) {
 }
 
 type Params map[string]string
+
+func copyParams(params Params) Params {
+	copied := make(Params, len(params))
+	for name, value := range params {
+		copied[name] = value
+	}
+	return copied
+}
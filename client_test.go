@@ -1,12 +1,23 @@
 package poloniex
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
 func TestClient(t *testing.T) {
 	Convey("Given new client", t, func() {
 		keys := []Key{{"key", "secret"}}
@@ -23,6 +34,7 @@ func TestClient(t *testing.T) {
 
 		Convey("Should SetTransport", func() {
 			c.SetTransport(&http.Transport{})
+			So(c.httpClient.Transport, ShouldHaveSameTypeAs, &http.Transport{})
 		})
 
 		Convey("Should SetRequestRateLimit", func() {
@@ -31,3 +43,72 @@ func TestClient(t *testing.T) {
 		})
 	})
 }
+
+func TestNewClient(t *testing.T) {
+	Convey("Clients should not share HTTP settings", t, func() {
+		first := NewClient([]Key{}, WithTimeout(time.Second))
+		second := NewClient([]Key{})
+		second.SetTimeout(time.Minute)
+
+		So(first.httpClient, ShouldNotPointTo, second.httpClient)
+		So(first.httpClient.Timeout, ShouldEqual, time.Second)
+		So(second.httpClient.Timeout, ShouldEqual, time.Minute)
+	})
+
+	Convey("Should copy given HTTP client", t, func() {
+		httpClient := &http.Client{Timeout: time.Second}
+		client := NewClient([]Key{}, WithHTTPClient(httpClient), WithTimeout(time.Minute))
+
+		So(client.httpClient.Timeout, ShouldEqual, time.Minute)
+		So(httpClient.Timeout, ShouldEqual, time.Second)
+	})
+
+	Convey("Should send requests through given round tripper", t, func() {
+		var requested string
+		client := NewClient([]Key{}, WithTransport(roundTripFunc(func(request *http.Request) (*http.Response, error) {
+			requested = request.URL.String()
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{"BTC_ETH":{"id":148,"last":"0.1"}}`)),
+			}, nil
+		})))
+
+		ticker, err := client.Ticker()
+		So(err, ShouldBeNil)
+		So(requested, ShouldEqual, "https://poloniex.com/public?command=returnTicker")
+		So(ticker["BTC_ETH"].Id, ShouldEqual, 148)
+	})
+}
+
+func TestClient_endpoints(t *testing.T) {
+	Convey("Setup plain HTTP mock", t, func() {
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			fmt.Fprint(w, `{}`)
+		}))
+		defer server.Close()
+
+		Convey("Should send requests to base URL", func() {
+			client := NewClient([]Key{Key{"key", "secret"}}, WithBaseURL(server.URL+"/"))
+
+			_, err := client.Ticker()
+			So(err, ShouldBeNil)
+			_, err = client.Balances()
+			So(err, ShouldBeNil)
+			So(paths, ShouldResemble, []string{"/public", "/tradingApi"})
+		})
+
+		Convey("Should send requests to separate endpoints", func() {
+			client := NewClient([]Key{Key{"key", "secret"}},
+				WithPublicEndpoint(server.URL+"/mirror/public"),
+				WithTradingEndpoint(server.URL+"/mirror/trading"))
+
+			_, err := client.Ticker()
+			So(err, ShouldBeNil)
+			_, err = client.Balances()
+			So(err, ShouldBeNil)
+			So(paths, ShouldResemble, []string{"/mirror/public", "/mirror/trading"})
+		})
+	})
+}
@@ -0,0 +1,126 @@
+package poloniex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type ErrorKind int
+
+const (
+	ErrorKindUnknown ErrorKind = iota
+	ErrorKindInvalidNonce
+	ErrorKindInsufficientFunds
+	ErrorKindInvalidCurrencyPair
+	ErrorKindRateLimited
+	ErrorKindOrderNotFound
+	ErrorKindInvalidApiKey
+	ErrorKindMaintenance
+)
+
+var (
+	ErrInvalidNonce        = errors.New("invalid nonce")
+	ErrInsufficientFunds   = errors.New("insufficient funds")
+	ErrInvalidCurrencyPair = errors.New("invalid currency pair")
+	ErrRateLimited         = errors.New("rate limited")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrInvalidApiKey       = errors.New("invalid API key")
+	ErrMaintenance         = errors.New("exchange is under maintenance")
+)
+
+var errorKindSentinels = map[ErrorKind]error{
+	ErrorKindInvalidNonce:        ErrInvalidNonce,
+	ErrorKindInsufficientFunds:   ErrInsufficientFunds,
+	ErrorKindInvalidCurrencyPair: ErrInvalidCurrencyPair,
+	ErrorKindRateLimited:         ErrRateLimited,
+	ErrorKindOrderNotFound:       ErrOrderNotFound,
+	ErrorKindInvalidApiKey:       ErrInvalidApiKey,
+	ErrorKindMaintenance:         ErrMaintenance,
+}
+
+// errorKindPatterns are matched against the lower-cased message returned by the exchange
+var errorKindPatterns = []struct {
+	kind     ErrorKind
+	patterns []string
+}{
+	{ErrorKindInvalidNonce, []string{"nonce must be greater", "invalid nonce"}},
+	{ErrorKindInsufficientFunds, []string{"not enough", "insufficient"}},
+	{ErrorKindInvalidCurrencyPair, []string{"invalid currency pair", "invalid currencypair"}},
+	{ErrorKindRateLimited, []string{"please do not make more than", "too many requests"}},
+	{ErrorKindOrderNotFound, []string{"invalid order number", "order not found"}},
+	{ErrorKindInvalidApiKey, []string{"invalid api key", "invalid key"}},
+	{ErrorKindMaintenance, []string{"maintenance"}},
+}
+
+// APIError is returned when the exchange rejects a request
+// either with an error body or with a non-successful HTTP status.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Command    string
+	Kind       ErrorKind
+}
+
+func (apiError *APIError) Error() string {
+	return fmt.Sprintf("%s: %s", apiError.Command, apiError.Message)
+}
+
+// Is makes APIError comparable to the Err* sentinels with errors.Is
+func (apiError *APIError) Is(target error) bool {
+	sentinel, ok := errorKindSentinels[apiError.Kind]
+	return ok && sentinel == target
+}
+
+func newApiError(command string, statusCode int, message string) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    message,
+		Command:    command,
+		Kind:       classifyError(statusCode, message),
+	}
+}
+
+func classifyError(statusCode int, message string) ErrorKind {
+	lowerMessage := strings.ToLower(message)
+	for _, kindPatterns := range errorKindPatterns {
+		for _, pattern := range kindPatterns.patterns {
+			if strings.Contains(lowerMessage, pattern) {
+				return kindPatterns.kind
+			}
+		}
+	}
+
+	switch statusCode {
+	case http.StatusTooManyRequests:
+		return ErrorKindRateLimited
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorKindInvalidApiKey
+	case http.StatusServiceUnavailable:
+		return ErrorKindMaintenance
+	}
+
+	return ErrorKindUnknown
+}
+
+type errorResponse struct {
+	Error *string
+}
+
+// checkResponse returns an *APIError when body carries an exchange error
+// or the status code is not successful
+func checkResponse(command string, statusCode int, body []byte) error {
+	errorResponse := errorResponse{}
+	json.Unmarshal(body, &errorResponse)
+	if errorResponse.Error != nil {
+		return newApiError(command, statusCode, *errorResponse.Error)
+	}
+
+	if statusCode >= http.StatusBadRequest {
+		return newApiError(command, statusCode, http.StatusText(statusCode))
+	}
+
+	return nil
+}
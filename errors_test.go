@@ -0,0 +1,81 @@
+package poloniex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_classifyError(t *testing.T) {
+	type args struct {
+		statusCode int
+		message    string
+	}
+	tests := []struct {
+		name string
+		args args
+		want ErrorKind
+	}{
+		{"invalid nonce", args{http.StatusOK, "Nonce must be greater than 1506000000000000. You provided 1505000000000000."}, ErrorKindInvalidNonce},
+		{"insufficient funds", args{http.StatusOK, "Not enough BTC."}, ErrorKindInsufficientFunds},
+		{"invalid currency pair", args{http.StatusOK, "Invalid currency pair."}, ErrorKindInvalidCurrencyPair},
+		{"rate limited by message", args{http.StatusOK, "Please do not make more than 8 API calls per second."}, ErrorKindRateLimited},
+		{"rate limited by status", args{http.StatusTooManyRequests, ""}, ErrorKindRateLimited},
+		{"order not found", args{http.StatusOK, "Invalid order number, or you are not the person who placed the order."}, ErrorKindOrderNotFound},
+		{"invalid API key", args{http.StatusForbidden, "Invalid API key/secret pair."}, ErrorKindInvalidApiKey},
+		{"maintenance", args{http.StatusServiceUnavailable, "Service Unavailable"}, ErrorKindMaintenance},
+		{"unknown", args{http.StatusOK, "some"}, ErrorKindUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyError(tt.args.statusCode, tt.args.message); got != tt.want {
+				t.Errorf("classifyError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	Convey("Given API error", t, func() {
+		var err error = newApiError("buy", http.StatusOK, "Not enough BTC.")
+
+		Convey("It should match its sentinel", func() {
+			So(errors.Is(err, ErrInsufficientFunds), ShouldBeTrue)
+			So(errors.Is(err, ErrInvalidNonce), ShouldBeFalse)
+		})
+
+		Convey("It should be extractable with errors.As", func() {
+			var apiError *APIError
+			So(errors.As(fmt.Errorf("wrapped: %w", err), &apiError), ShouldBeTrue)
+			So(apiError.Command, ShouldEqual, "buy")
+			So(apiError.Message, ShouldEqual, "Not enough BTC.")
+		})
+	})
+
+	Convey("Setup failing server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				fmt.Fprint(w, `<html>Bad Gateway</html>`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{})
+		client.SetTransport(transportForTesting(server))
+		client.SetRetryPolicy(RetryPolicy{})
+
+		Convey("Should return APIError with status code", func() {
+			_, err := client.Ticker()
+
+			var apiError *APIError
+			So(errors.As(err, &apiError), ShouldBeTrue)
+			So(apiError.StatusCode, ShouldEqual, http.StatusBadGateway)
+			So(apiError.Command, ShouldEqual, "returnTicker")
+		})
+	})
+}
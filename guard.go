@@ -0,0 +1,302 @@
+package poloniex
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultGuardConfirmTimeout = 5 * time.Minute
+	guardDailyPeriod           = 24 * time.Hour
+	// guardClockSkew tolerates exchange timestamps earlier than the local submission time
+	guardClockSkew = 10 * time.Minute
+)
+
+var (
+	ErrAddressNotAllowed     = errors.New("withdrawal address is not allowed")
+	ErrWithdrawalLimit       = errors.New("withdrawal limit exceeded")
+	ErrCurrencyUnavailable   = errors.New("currency is not available for withdrawal")
+	ErrWithdrawalNotPrepared = errors.New("withdrawal is not prepared or has expired")
+)
+
+// WithdrawalLimit caps withdrawals of a currency, zero values mean no cap
+type WithdrawalLimit struct {
+	Single decimal.Decimal
+	// Daily caps the sum of withdrawals over the last 24 hours, including the checked one
+	Daily decimal.Decimal
+}
+
+// submittedWithdrawal is a withdrawal sent by the guard, counted towards daily limits until the exchange lists it
+type submittedWithdrawal struct {
+	currency string
+	address  string
+	amount   decimal.Decimal
+	at       time.Time
+}
+
+type PreparedWithdrawal struct {
+	Token     string
+	Currency  string
+	Address   string
+	Amount    decimal.Decimal
+	ExpiresAt time.Time
+}
+
+// WithdrawalGuard checks withdrawals against an address allow-list, amount limits and currency state before sending them.
+// Currencies without allowed addresses can not be withdrawn through the guard.
+type WithdrawalGuard struct {
+	client *Client
+
+	// ConfirmTimeout is how long a prepared withdrawal waits for confirmation
+	ConfirmTimeout time.Duration
+
+	mutex     sync.Mutex
+	addresses map[string]map[string]bool
+	limits    map[string]WithdrawalLimit
+	prepared  map[string]PreparedWithdrawal
+	submitted []submittedWithdrawal
+}
+
+func NewWithdrawalGuard(client *Client) *WithdrawalGuard {
+	return &WithdrawalGuard{
+		client:         client,
+		ConfirmTimeout: defaultGuardConfirmTimeout,
+		addresses:      make(map[string]map[string]bool),
+		limits:         make(map[string]WithdrawalLimit),
+		prepared:       make(map[string]PreparedWithdrawal),
+	}
+}
+
+func (guard *WithdrawalGuard) AllowAddress(currency, address string) {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	if guard.addresses[currency] == nil {
+		guard.addresses[currency] = make(map[string]bool)
+	}
+	guard.addresses[currency][address] = true
+}
+
+func (guard *WithdrawalGuard) SetLimit(currency string, limit WithdrawalLimit) {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	guard.limits[currency] = limit
+}
+
+// Check reports why the withdrawal would be rejected, or nil when it passes every check
+func (guard *WithdrawalGuard) Check(ctx context.Context, currency, address string, amount decimal.Decimal) error {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	return guard.check(ctx, currency, address, amount)
+}
+
+// Withdraw checks the withdrawal and sends it. Checks and submission are serialized and withdrawals sent by the guard
+// count towards daily limits before the exchange lists them, so withdrawals through one guard can not exceed
+// the daily limit together.
+func (guard *WithdrawalGuard) Withdraw(ctx context.Context, currency, address string, amount decimal.Decimal) (response string, err error) {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	if err = guard.check(ctx, currency, address, amount); err != nil {
+		return "", err
+	}
+
+	return guard.submit(ctx, currency, address, amount)
+}
+
+// Prepare checks the withdrawal and holds it until Confirm is called with the returned token
+func (guard *WithdrawalGuard) Prepare(ctx context.Context, currency, address string, amount decimal.Decimal) (prepared PreparedWithdrawal, err error) {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	guard.pruneExpired(time.Now())
+
+	if err = guard.check(ctx, currency, address, amount); err != nil {
+		return prepared, err
+	}
+
+	token := make([]byte, 16)
+	if _, err = rand.Read(token); err != nil {
+		return prepared, err
+	}
+
+	prepared = PreparedWithdrawal{
+		Token:     hex.EncodeToString(token),
+		Currency:  currency,
+		Address:   address,
+		Amount:    amount,
+		ExpiresAt: time.Now().Add(guard.ConfirmTimeout),
+	}
+	guard.prepared[prepared.Token] = prepared
+
+	return prepared, nil
+}
+
+// Confirm sends a prepared withdrawal once. Checks are repeated, since limits and currency state may have changed meanwhile.
+func (guard *WithdrawalGuard) Confirm(ctx context.Context, token string) (response string, err error) {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	prepared, ok := guard.prepared[token]
+	delete(guard.prepared, token)
+	guard.pruneExpired(time.Now())
+	if !ok || time.Now().After(prepared.ExpiresAt) {
+		return "", ErrWithdrawalNotPrepared
+	}
+
+	if err = guard.check(ctx, prepared.Currency, prepared.Address, prepared.Amount); err != nil {
+		return "", err
+	}
+
+	return guard.submit(ctx, prepared.Currency, prepared.Address, prepared.Amount)
+}
+
+// Cancel drops a prepared withdrawal
+func (guard *WithdrawalGuard) Cancel(token string) {
+	guard.mutex.Lock()
+	defer guard.mutex.Unlock()
+
+	delete(guard.prepared, token)
+}
+
+// pruneExpired drops prepared withdrawals which can not be confirmed anymore
+func (guard *WithdrawalGuard) pruneExpired(now time.Time) {
+	for token, prepared := range guard.prepared {
+		if now.After(prepared.ExpiresAt) {
+			delete(guard.prepared, token)
+		}
+	}
+}
+
+func (guard *WithdrawalGuard) check(ctx context.Context, currency, address string, amount decimal.Decimal) error {
+	if !amount.GreaterThan(decimal.Zero) {
+		return fmt.Errorf("withdrawal amount must be positive, got %s", amount)
+	}
+
+	if !guard.addresses[currency][address] {
+		return fmt.Errorf("%w: %s address %s", ErrAddressNotAllowed, currency, address)
+	}
+
+	limit := guard.limits[currency]
+	if limit.Single.GreaterThan(decimal.Zero) && amount.GreaterThan(limit.Single) {
+		return fmt.Errorf("%w: %s %s is over the single withdrawal limit of %s", ErrWithdrawalLimit, amount, currency, limit.Single)
+	}
+
+	currencies, err := guard.client.CurrenciesCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	info, ok := currencies[currency]
+	switch {
+	case !ok:
+		return fmt.Errorf("%w: %s is unknown", ErrCurrencyUnavailable, currency)
+	case bool(info.Disabled):
+		return fmt.Errorf("%w: %s is disabled", ErrCurrencyUnavailable, currency)
+	case bool(info.Frozen):
+		return fmt.Errorf("%w: %s is frozen", ErrCurrencyUnavailable, currency)
+	case bool(info.Delisted):
+		return fmt.Errorf("%w: %s is delisted", ErrCurrencyUnavailable, currency)
+	}
+
+	if !limit.Daily.GreaterThan(decimal.Zero) && !info.MaxDailyWithdrawal.GreaterThan(decimal.Zero) {
+		return nil
+	}
+
+	withdrawn, err := guard.withdrawnSince(ctx, currency, time.Now().Add(-guardDailyPeriod))
+	if err != nil {
+		return err
+	}
+
+	total := withdrawn.Add(amount)
+	if limit.Daily.GreaterThan(decimal.Zero) && total.GreaterThan(limit.Daily) {
+		return fmt.Errorf("%w: %s %s withdrawn in 24 hours would be over the daily limit of %s", ErrWithdrawalLimit, total, currency, limit.Daily)
+	}
+	if info.MaxDailyWithdrawal.GreaterThan(decimal.Zero) && total.GreaterThan(info.MaxDailyWithdrawal) {
+		return fmt.Errorf("%w: %s %s withdrawn in 24 hours would be over the exchange daily limit of %s", ErrWithdrawalLimit, total, currency, info.MaxDailyWithdrawal)
+	}
+
+	return nil
+}
+
+// submit sends the withdrawal and records it. Failures other than exchange rejections are recorded as well,
+// since the withdrawal may have been sent anyway.
+func (guard *WithdrawalGuard) submit(ctx context.Context, currency, address string, amount decimal.Decimal) (response string, err error) {
+	at := time.Now()
+	response, err = guard.client.WithdrawCtx(ctx, currency, address, amount)
+
+	var apiError *APIError
+	if err == nil || !errors.As(err, &apiError) {
+		guard.submitted = append(guard.submitted, submittedWithdrawal{currency: currency, address: address, amount: amount, at: at})
+	}
+
+	return response, err
+}
+
+// withdrawnSince sums withdrawals of the currency which are not cancelled or failed,
+// including those sent by the guard and not listed by the exchange yet
+func (guard *WithdrawalGuard) withdrawnSince(ctx context.Context, currency string, since time.Time) (sum decimal.Decimal, err error) {
+	response, err := guard.client.DepositsWithdrawalsBetweenCtx(ctx, since, time.Time{})
+	if err != nil {
+		return sum, err
+	}
+
+	for _, withdrawal := range response.Withdrawals {
+		if withdrawal.Currency != currency ||
+			withdrawal.Status == TransferStatusCancelled ||
+			withdrawal.Status == TransferStatusFailed {
+			continue
+		}
+		sum = sum.Add(withdrawal.Amount)
+	}
+
+	guard.pruneSubmitted(since)
+
+	// Every listed withdrawal, cancelled ones included, accounts for at most one submission
+	listed := make(map[uint]bool)
+	for _, submitted := range guard.submitted {
+		if submitted.currency != currency || guard.listed(submitted, response.Withdrawals, listed) {
+			continue
+		}
+		sum = sum.Add(submitted.amount)
+	}
+
+	return sum, nil
+}
+
+// listed reports whether the exchange lists the submission, marking the matching withdrawal
+func (guard *WithdrawalGuard) listed(submitted submittedWithdrawal, withdrawals []*Withdrawal, listed map[uint]bool) bool {
+	for _, withdrawal := range withdrawals {
+		if listed[withdrawal.WithdrawalNumber] ||
+			withdrawal.Currency != submitted.currency ||
+			withdrawal.Address != submitted.address ||
+			!withdrawal.Amount.Equal(submitted.amount) ||
+			withdrawal.Timestamp.Before(submitted.at.Add(-guardClockSkew)) {
+			continue
+		}
+
+		listed[withdrawal.WithdrawalNumber] = true
+		return true
+	}
+
+	return false
+}
+
+func (guard *WithdrawalGuard) pruneSubmitted(since time.Time) {
+	kept := guard.submitted[:0]
+	for _, submitted := range guard.submitted {
+		if !submitted.at.Before(since) {
+			kept = append(kept, submitted)
+		}
+	}
+	guard.submitted = kept
+}
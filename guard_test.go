@@ -0,0 +1,163 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithdrawalGuard(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		currencies := `{"BTC":{"id":28,"name":"Bitcoin","txFee":"0.00050000","minConf":3,"maxDailyWithdrawal":"10.00000000","disabled":0,"delisted":0,"frozen":0},
+"XMR":{"id":254,"name":"Monero","txFee":"0.01","minConf":6,"maxDailyWithdrawal":"0","disabled":0,"delisted":0,"frozen":1}}`
+		var withdrawals []string
+		listed := ""
+
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("command") == "returnCurrencies" {
+					fmt.Fprint(w, currencies)
+					return
+				}
+
+				r.ParseForm()
+				switch r.PostForm.Get("command") {
+				case "withdraw":
+					withdrawals = append(withdrawals, r.PostForm.Get("address")+" "+r.PostForm.Get("amount"))
+					fmt.Fprintf(w, `{"response":"Withdrew %s BTC."}`, r.PostForm.Get("amount"))
+				case "returnDepositsWithdrawals":
+					fmt.Fprintf(w, `{"deposits":[],"withdrawals":[
+{"withdrawalNumber":1,"currency":"BTC","address":"xyz","amount":"4.00000000","fee":"0.0001","timestamp":1506010932,"status":"COMPLETE: aaa"},
+{"withdrawalNumber":2,"currency":"BTC","address":"xyz","amount":"3.00000000","fee":"0.0001","timestamp":1506010933,"status":"CANCELED"},
+{"withdrawalNumber":3,"currency":"ETH","address":"xyz","amount":"5.00000000","fee":"0.0001","timestamp":1506010934,"status":"PENDING"}%s]}`, listed)
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		guard := NewWithdrawalGuard(client)
+		guard.AllowAddress("BTC", "xyz")
+		guard.AllowAddress("XMR", "abc")
+		guard.SetLimit("BTC", WithdrawalLimit{Single: decimal.New(2, 0), Daily: decimal.New(5, 0)})
+
+		Convey("Should send allowed withdrawal", func() {
+			response, err := guard.Withdraw(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			So(response, ShouldEqual, "Withdrew 1 BTC.")
+			So(withdrawals, ShouldResemble, []string{"xyz 1"})
+		})
+
+		Convey("Should reject unknown address", func() {
+			_, err := guard.Withdraw(context.Background(), "BTC", "other", decimal.New(1, 0))
+			So(errors.Is(err, ErrAddressNotAllowed), ShouldBeTrue)
+			So(withdrawals, ShouldBeEmpty)
+		})
+
+		Convey("Should reject amount over single limit", func() {
+			_, err := guard.Withdraw(context.Background(), "BTC", "xyz", decimal.New(3, 0))
+			So(errors.Is(err, ErrWithdrawalLimit), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "single")
+		})
+
+		Convey("Should reject amount over daily limit", func() {
+			err := guard.Check(context.Background(), "BTC", "xyz", decimal.New(15, -1))
+			So(errors.Is(err, ErrWithdrawalLimit), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "5.5 BTC withdrawn in 24 hours")
+		})
+
+		Convey("Should count own withdrawals not listed by the exchange yet", func() {
+			_, err := guard.Withdraw(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+
+			_, err = guard.Withdraw(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(errors.Is(err, ErrWithdrawalLimit), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "6 BTC withdrawn in 24 hours")
+			So(withdrawals, ShouldResemble, []string{"xyz 1"})
+
+			guard.SetLimit("BTC", WithdrawalLimit{})
+			err = guard.Check(context.Background(), "BTC", "xyz", decimal.New(55, -1))
+			So(errors.Is(err, ErrWithdrawalLimit), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "exchange daily limit")
+		})
+
+		Convey("Should not count own withdrawals twice once listed by the exchange", func() {
+			_, err := guard.Withdraw(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+
+			listed = fmt.Sprintf(`,{"withdrawalNumber":4,"currency":"BTC","address":"xyz","amount":"1.00000000","fee":"0.0001","timestamp":%d,"status":"PENDING"}`, time.Now().Unix())
+			err = guard.Check(context.Background(), "BTC", "xyz", decimal.New(5, -1))
+			So(errors.Is(err, ErrWithdrawalLimit), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "5.5 BTC withdrawn in 24 hours")
+		})
+
+		Convey("Should reject amount over exchange daily limit", func() {
+			guard.SetLimit("BTC", WithdrawalLimit{})
+			err := guard.Check(context.Background(), "BTC", "xyz", decimal.New(7, 0))
+			So(errors.Is(err, ErrWithdrawalLimit), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "exchange daily limit")
+		})
+
+		Convey("Should reject frozen currency", func() {
+			err := guard.Check(context.Background(), "XMR", "abc", decimal.New(1, 0))
+			So(errors.Is(err, ErrCurrencyUnavailable), ShouldBeTrue)
+			So(err.Error(), ShouldContainSubstring, "frozen")
+		})
+
+		Convey("Should send prepared withdrawal once on confirmation", func() {
+			prepared, err := guard.Prepare(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			So(prepared.Token, ShouldHaveLength, 32)
+			So(withdrawals, ShouldBeEmpty)
+
+			_, err = guard.Confirm(context.Background(), prepared.Token)
+			So(err, ShouldBeNil)
+			So(withdrawals, ShouldResemble, []string{"xyz 1"})
+
+			_, err = guard.Confirm(context.Background(), prepared.Token)
+			So(err, ShouldEqual, ErrWithdrawalNotPrepared)
+			So(withdrawals, ShouldHaveLength, 1)
+		})
+
+		Convey("Should drop abandoned prepared withdrawals after expiry", func() {
+			guard.ConfirmTimeout = -time.Second
+			_, err := guard.Prepare(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			So(guard.prepared, ShouldHaveLength, 1)
+
+			guard.ConfirmTimeout = time.Minute
+			pending, err := guard.Prepare(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			So(guard.prepared, ShouldHaveLength, 1)
+			So(guard.prepared, ShouldContainKey, pending.Token)
+		})
+
+		Convey("Should not send expired or cancelled withdrawal", func() {
+			guard.ConfirmTimeout = -time.Second
+			expired, err := guard.Prepare(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+
+			guard.ConfirmTimeout = time.Minute
+			cancelled, err := guard.Prepare(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			guard.Cancel(cancelled.Token)
+
+			_, err = guard.Confirm(context.Background(), expired.Token)
+			So(err, ShouldEqual, ErrWithdrawalNotPrepared)
+			So(guard.prepared, ShouldBeEmpty)
+			_, err = guard.Confirm(context.Background(), cancelled.Token)
+			So(err, ShouldEqual, ErrWithdrawalNotPrepared)
+			So(withdrawals, ShouldBeEmpty)
+		})
+	})
+}
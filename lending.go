@@ -0,0 +1,229 @@
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type LoanOrder struct {
+	Rate     decimal.Decimal
+	Amount   decimal.Decimal
+	RangeMin uint
+	RangeMax uint
+}
+
+type LoanOrders struct {
+	Offers  []LoanOrder
+	Demands []LoanOrder
+}
+
+func (client *Client) LoanOrders(currency string) (loanOrders LoanOrders, err error) {
+	return client.LoanOrdersCtx(context.Background(), currency)
+}
+
+func (client *Client) LoanOrdersCtx(ctx context.Context, currency string) (loanOrders LoanOrders, err error) {
+	err = client.publicApiRequest(ctx, &loanOrders, "returnLoanOrders", Params{
+		"currency": currency,
+	})
+	return
+}
+
+// CreateLoanOffer offers amount of currency for lending over duration days and returns the offer id
+func (client *Client) CreateLoanOffer(currency string, amount decimal.Decimal, duration uint, autoRenew bool, lendingRate decimal.Decimal) (orderId uint64, err error) {
+	return client.CreateLoanOfferCtx(context.Background(), currency, amount, duration, autoRenew, lendingRate)
+}
+
+func (client *Client) CreateLoanOfferCtx(ctx context.Context, currency string, amount decimal.Decimal, duration uint, autoRenew bool, lendingRate decimal.Decimal) (orderId uint64, err error) {
+	result := struct {
+		Success convertibleBool
+		Message string
+		OrderId convertibleUint `json:"orderID"`
+	}{}
+
+	params := Params{
+		"currency":    currency,
+		"amount":      amount.String(),
+		"duration":    fmt.Sprintf("%d", duration),
+		"autoRenew":   "0",
+		"lendingRate": lendingRate.String(),
+	}
+
+	if autoRenew {
+		params["autoRenew"] = "1"
+	}
+
+	if err = client.tradingApiRequest(ctx, &result, "createLoanOffer", params); err != nil {
+		return uint64(result.OrderId), err
+	}
+
+	if !result.Success {
+		err = fmt.Errorf("createLoanOffer for currency %s is not successful: %s", currency, result.Message)
+	}
+
+	return uint64(result.OrderId), err
+}
+
+func (client *Client) CancelLoanOffer(orderNumber uint64) (success bool, err error) {
+	return client.CancelLoanOfferCtx(context.Background(), orderNumber)
+}
+
+func (client *Client) CancelLoanOfferCtx(ctx context.Context, orderNumber uint64) (success bool, err error) {
+	result := struct {
+		Success convertibleBool
+	}{}
+	err = client.tradingApiRequest(ctx, &result, "cancelLoanOffer",
+		Params{"orderNumber": fmt.Sprintf("%d", orderNumber)})
+	success = bool(result.Success)
+	return
+}
+
+type LoanOffer struct {
+	Id        uint64          `json:"id"`
+	Rate      decimal.Decimal `json:"rate"`
+	Amount    decimal.Decimal `json:"amount"`
+	Duration  uint            `json:"duration"`
+	AutoRenew convertibleBool `json:"autoRenew"`
+	Date      time.Time       `json:"date"`
+}
+
+func (offer *LoanOffer) UnmarshalJSON(data []byte) error {
+	type plainLoanOffer LoanOffer
+	withDate := struct {
+		*plainLoanOffer
+		Date convertibleTime `json:"date"`
+	}{plainLoanOffer: (*plainLoanOffer)(offer)}
+
+	if err := json.Unmarshal(data, &withDate); err != nil {
+		return err
+	}
+
+	offer.Date = time.Time(withDate.Date)
+	return nil
+}
+
+// OpenLoanOffers returns open loan offers keyed by currency
+func (client *Client) OpenLoanOffers() (offers map[string][]LoanOffer, err error) {
+	return client.OpenLoanOffersCtx(context.Background())
+}
+
+func (client *Client) OpenLoanOffersCtx(ctx context.Context) (offers map[string][]LoanOffer, err error) {
+	err = client.tradingApiRequest(ctx, &offers, "returnOpenLoanOffers")
+	return
+}
+
+type ActiveLoan struct {
+	Id        uint64          `json:"id"`
+	Currency  string          `json:"currency"`
+	Rate      decimal.Decimal `json:"rate"`
+	Amount    decimal.Decimal `json:"amount"`
+	Duration  uint            `json:"range"`
+	AutoRenew convertibleBool `json:"autoRenew"`
+	Date      time.Time       `json:"date"`
+	Fees      decimal.Decimal `json:"fees"`
+}
+
+func (loan *ActiveLoan) UnmarshalJSON(data []byte) error {
+	type plainActiveLoan ActiveLoan
+	withDate := struct {
+		*plainActiveLoan
+		Date convertibleTime `json:"date"`
+	}{plainActiveLoan: (*plainActiveLoan)(loan)}
+
+	if err := json.Unmarshal(data, &withDate); err != nil {
+		return err
+	}
+
+	loan.Date = time.Time(withDate.Date)
+	return nil
+}
+
+type ActiveLoans struct {
+	Provided []ActiveLoan `json:"provided"`
+	Used     []ActiveLoan `json:"used"`
+}
+
+func (client *Client) ActiveLoans() (loans ActiveLoans, err error) {
+	return client.ActiveLoansCtx(context.Background())
+}
+
+func (client *Client) ActiveLoansCtx(ctx context.Context) (loans ActiveLoans, err error) {
+	err = client.tradingApiRequest(ctx, &loans, "returnActiveLoans")
+	return
+}
+
+type LendingHistoryEntry struct {
+	Id       uint64          `json:"id"`
+	Currency string          `json:"currency"`
+	Rate     decimal.Decimal `json:"rate"`
+	Amount   decimal.Decimal `json:"amount"`
+	Duration decimal.Decimal `json:"duration"`
+	Interest decimal.Decimal `json:"interest"`
+	Fee      decimal.Decimal `json:"fee"`
+	Earned   decimal.Decimal `json:"earned"`
+	Open     time.Time       `json:"open"`
+	Close    time.Time       `json:"close"`
+}
+
+func (entry *LendingHistoryEntry) UnmarshalJSON(data []byte) error {
+	type plainLendingHistoryEntry LendingHistoryEntry
+	withDates := struct {
+		*plainLendingHistoryEntry
+		Open  convertibleTime `json:"open"`
+		Close convertibleTime `json:"close"`
+	}{plainLendingHistoryEntry: (*plainLendingHistoryEntry)(entry)}
+
+	if err := json.Unmarshal(data, &withDates); err != nil {
+		return err
+	}
+
+	entry.Open = time.Time(withDates.Open)
+	entry.Close = time.Time(withDates.Close)
+	return nil
+}
+
+// LendingHistory returns closed loans between start and end Unix timestamps, a positive limit caps the number of entries
+func (client *Client) LendingHistory(start, end int64, limit uint) (history []LendingHistoryEntry, err error) {
+	return client.LendingHistoryCtx(context.Background(), start, end, limit)
+}
+
+func (client *Client) LendingHistoryCtx(ctx context.Context, start, end int64, limit uint) (history []LendingHistoryEntry, err error) {
+	params := Params{
+		"start": fmt.Sprintf("%d", start),
+		"end":   fmt.Sprintf("%d", end),
+	}
+
+	if limit > 0 {
+		params["limit"] = fmt.Sprintf("%d", limit)
+	}
+
+	err = client.tradingApiRequest(ctx, &history, "returnLendingHistory", params)
+	return
+}
+
+// ToggleAutoRenew switches auto-renew of the active loan and returns its new state
+func (client *Client) ToggleAutoRenew(orderNumber uint64) (autoRenew bool, err error) {
+	return client.ToggleAutoRenewCtx(context.Background(), orderNumber)
+}
+
+func (client *Client) ToggleAutoRenewCtx(ctx context.Context, orderNumber uint64) (autoRenew bool, err error) {
+	result := struct {
+		Success convertibleBool
+		Message convertibleBool
+	}{}
+
+	err = client.tradingApiRequest(ctx, &result, "toggleAutoRenew",
+		Params{"orderNumber": fmt.Sprintf("%d", orderNumber)})
+	if err != nil {
+		return false, err
+	}
+
+	if !result.Success {
+		err = fmt.Errorf("toggleAutoRenew for order %d is not successful", orderNumber)
+	}
+
+	return bool(result.Message), err
+}
@@ -0,0 +1,199 @@
+package poloniex
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClient_LoanOrders(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"offers":[{"rate":"0.00200000","amount":"64.66305732","rangeMin":2,"rangeMax":8}],"demands":[{"rate":"0.00170000","amount":"26.54848841","rangeMin":2,"rangeMax":2}]}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return loan orders", func() {
+			loanOrders, err := client.LoanOrders("BTC")
+			So(err, ShouldBeNil)
+
+			So(len(loanOrders.Offers), ShouldEqual, 1)
+			So(loanOrders.Offers[0].Rate.String(), ShouldEqual, "0.002")
+			So(loanOrders.Offers[0].RangeMax, ShouldEqual, 8)
+			So(len(loanOrders.Demands), ShouldEqual, 1)
+		})
+	})
+}
+
+func TestClient_CreateLoanOffer(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var autoRenew string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				autoRenew = r.PostForm.Get("autoRenew")
+				fmt.Fprint(w, `{"success":1,"message":"Loan order placed.","orderID":10590}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should create loan offer", func() {
+			orderId, err := client.CreateLoanOffer("BTC", decimal.New(1, 0), 2, true, decimal.New(15, -5))
+			So(err, ShouldBeNil)
+
+			So(orderId, ShouldEqual, 10590)
+			So(autoRenew, ShouldEqual, "1")
+		})
+
+		Convey("Should return error on unsuccessful response", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"success":0,"message":"Amount must be at least 0.01 BTC."}`)
+			}
+
+			_, err := client.CreateLoanOffer("BTC", decimal.New(1, -3), 2, false, decimal.New(15, -5))
+			So(err, ShouldBeError)
+		})
+	})
+}
+
+func TestClient_CancelLoanOffer(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"success":1,"message":"Loan offer canceled."}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should cancel loan offer", func() {
+			success, err := client.CancelLoanOffer(10590)
+			So(err, ShouldBeNil)
+			So(success, ShouldBeTrue)
+		})
+	})
+}
+
+func TestClient_OpenLoanOffers(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"BTC":[{"id":10595,"rate":"0.00020000","amount":"3.00000000","duration":2,"autoRenew":1,"date":"2015-05-10 23:33:50"}]}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return open loan offers", func() {
+			offers, err := client.OpenLoanOffers()
+			So(err, ShouldBeNil)
+
+			So(len(offers["BTC"]), ShouldEqual, 1)
+			So(offers["BTC"][0].Id, ShouldEqual, 10595)
+			So(bool(offers["BTC"][0].AutoRenew), ShouldBeTrue)
+			So(offers["BTC"][0].Date, ShouldEqual, time.Date(2015, 5, 10, 23, 33, 50, 0, time.UTC))
+		})
+
+		Convey("Should return no offers on empty response", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[]`)
+			}
+
+			offers, err := client.OpenLoanOffers()
+			So(err, ShouldBeNil)
+			So(len(offers), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestClient_ActiveLoans(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"provided":[{"id":75073,"currency":"LTC","rate":"0.00020000","amount":"0.72234880","range":2,"autoRenew":0,"date":"2015-05-10 23:45:05","fees":"0.00006000"}],"used":[{"id":75238,"currency":"BTC","rate":"0.00020000","amount":"0.04843834","range":2,"date":"2015-05-10 23:51:12","fees":"-0.00000001"}]}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return active loans", func() {
+			loans, err := client.ActiveLoans()
+			So(err, ShouldBeNil)
+
+			So(len(loans.Provided), ShouldEqual, 1)
+			So(loans.Provided[0].Duration, ShouldEqual, 2)
+			So(loans.Provided[0].Fees.String(), ShouldEqual, "0.00006")
+			So(len(loans.Used), ShouldEqual, 1)
+			So(loans.Used[0].Date, ShouldEqual, time.Date(2015, 5, 10, 23, 51, 12, 0, time.UTC))
+		})
+	})
+}
+
+func TestClient_LendingHistory(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"id":175589553,"currency":"BTC","rate":"0.00057400","amount":"0.04374404","duration":"0.47610000","interest":"0.00001196","fee":"-0.00000179","earned":"0.00001017","open":"2016-09-28 06:47:26","close":"2016-09-28 18:13:03"}]`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return lending history", func() {
+			history, err := client.LendingHistory(1, 2, 10)
+			So(err, ShouldBeNil)
+
+			So(len(history), ShouldEqual, 1)
+			So(history[0].Earned.String(), ShouldEqual, "0.00001017")
+			So(history[0].Open, ShouldEqual, time.Date(2016, 9, 28, 6, 47, 26, 0, time.UTC))
+			So(history[0].Close, ShouldEqual, time.Date(2016, 9, 28, 18, 13, 3, 0, time.UTC))
+		})
+	})
+}
+
+func TestClient_ToggleAutoRenew(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"success":1,"message":0}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should toggle auto-renew", func() {
+			autoRenew, err := client.ToggleAutoRenew(75073)
+			So(err, ShouldBeNil)
+			So(autoRenew, ShouldBeFalse)
+		})
+	})
+}
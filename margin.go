@@ -0,0 +1,154 @@
+package poloniex
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	MarginPositionLong  = "long"
+	MarginPositionShort = "short"
+	MarginPositionNone  = "none"
+)
+
+type MarginOrder struct {
+	OrderNumber     convertibleUint `json:"orderNumber"`
+	Message         string
+	ResultingTrades map[string][]Trade
+}
+
+// MarginBuy places a margin buy order, a positive lendingRate limits the rate of the loans taken for it
+func (client *Client) MarginBuy(currencyPair string, rate, amount, lendingRate decimal.Decimal) (marginOrder MarginOrder, err error) {
+	return client.MarginBuyCtx(context.Background(), currencyPair, rate, amount, lendingRate)
+}
+
+func (client *Client) MarginBuyCtx(ctx context.Context, currencyPair string, rate, amount, lendingRate decimal.Decimal) (marginOrder MarginOrder, err error) {
+	return client.marginOrder(ctx, "marginBuy", currencyPair, rate, amount, lendingRate)
+}
+
+// MarginSell places a margin sell order, a positive lendingRate limits the rate of the loans taken for it
+func (client *Client) MarginSell(currencyPair string, rate, amount, lendingRate decimal.Decimal) (marginOrder MarginOrder, err error) {
+	return client.MarginSellCtx(context.Background(), currencyPair, rate, amount, lendingRate)
+}
+
+func (client *Client) MarginSellCtx(ctx context.Context, currencyPair string, rate, amount, lendingRate decimal.Decimal) (marginOrder MarginOrder, err error) {
+	return client.marginOrder(ctx, "marginSell", currencyPair, rate, amount, lendingRate)
+}
+
+func (client *Client) marginOrder(ctx context.Context, method, currencyPair string, rate, amount, lendingRate decimal.Decimal) (marginOrder MarginOrder, err error) {
+	result := struct {
+		Success convertibleBool
+		MarginOrder
+	}{}
+
+	params := Params{
+		"currencyPair": currencyPair,
+		"rate":         rate.String(),
+		"amount":       amount.String(),
+	}
+
+	if lendingRate.GreaterThan(decimal.Zero) {
+		params["lendingRate"] = lendingRate.String()
+	}
+
+	if err = client.tradingApiRequest(ctx, &result, method, params); err != nil {
+		return result.MarginOrder, err
+	}
+
+	if !result.Success {
+		err = fmt.Errorf("%s for currency pair %s is not successful: %s", method, currencyPair, result.Message)
+	}
+
+	return result.MarginOrder, err
+}
+
+type MarginPosition struct {
+	Amount           decimal.Decimal
+	Total            decimal.Decimal
+	BasePrice        decimal.Decimal
+	LiquidationPrice decimal.Decimal
+	PL               decimal.Decimal `json:"pl"`
+	LendingFees      decimal.Decimal
+	Type             string
+}
+
+func (client *Client) MarginPosition(currencyPair string) (position MarginPosition, err error) {
+	return client.MarginPositionCtx(context.Background(), currencyPair)
+}
+
+func (client *Client) MarginPositionCtx(ctx context.Context, currencyPair string) (position MarginPosition, err error) {
+	err = client.tradingApiRequest(ctx, &position, "getMarginPosition", Params{
+		"currencyPair": currencyPair,
+	})
+	return
+}
+
+func (client *Client) MarginPositionAll() (positions map[string]MarginPosition, err error) {
+	return client.MarginPositionAllCtx(context.Background())
+}
+
+func (client *Client) MarginPositionAllCtx(ctx context.Context) (positions map[string]MarginPosition, err error) {
+	err = client.tradingApiRequest(ctx, &positions, "getMarginPosition", Params{
+		"currencyPair": "all",
+	})
+	return
+}
+
+type ClosedMarginPosition struct {
+	Message         string
+	ResultingTrades map[string][]Trade
+}
+
+func (client *Client) CloseMarginPosition(currencyPair string) (closedPosition ClosedMarginPosition, err error) {
+	return client.CloseMarginPositionCtx(context.Background(), currencyPair)
+}
+
+func (client *Client) CloseMarginPositionCtx(ctx context.Context, currencyPair string) (closedPosition ClosedMarginPosition, err error) {
+	result := struct {
+		Success convertibleBool
+		ClosedMarginPosition
+	}{}
+
+	err = client.tradingApiRequest(ctx, &result, "closeMarginPosition", Params{
+		"currencyPair": currencyPair,
+	})
+	if err != nil {
+		return result.ClosedMarginPosition, err
+	}
+
+	if !result.Success {
+		err = fmt.Errorf("closeMarginPosition for currency pair %s is not successful: %s", currencyPair, result.Message)
+	}
+
+	return result.ClosedMarginPosition, err
+}
+
+type MarginAccountSummary struct {
+	TotalValue         decimal.Decimal
+	PL                 decimal.Decimal `json:"pl"`
+	LendingFees        decimal.Decimal
+	NetValue           decimal.Decimal
+	TotalBorrowedValue decimal.Decimal
+	CurrentMargin      decimal.Decimal
+}
+
+func (client *Client) MarginAccountSummary() (summary MarginAccountSummary, err error) {
+	return client.MarginAccountSummaryCtx(context.Background())
+}
+
+func (client *Client) MarginAccountSummaryCtx(ctx context.Context) (summary MarginAccountSummary, err error) {
+	err = client.tradingApiRequest(ctx, &summary, "returnMarginAccountSummary")
+	return
+}
+
+// TradableBalances returns balances available for margin trading keyed by currency pair and currency
+func (client *Client) TradableBalances() (balances map[string]map[string]decimal.Decimal, err error) {
+	return client.TradableBalancesCtx(context.Background())
+}
+
+func (client *Client) TradableBalancesCtx(ctx context.Context) (balances map[string]map[string]decimal.Decimal, err error) {
+	err = client.tradingApiRequest(ctx, &balances, "returnTradableBalances")
+	return
+}
@@ -0,0 +1,173 @@
+package poloniex
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClient_MarginBuy(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var form map[string]string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				form = map[string]string{}
+				for name := range r.PostForm {
+					form[name] = r.PostForm.Get(name)
+				}
+				fmt.Fprint(w, `{"success":1,"message":"Margin order placed.","orderNumber":"154407998","resultingTrades":{"BTC_DASH":[{"amount":"1.00000000","date":"2015-05-10 22:47:05","rate":"0.01383692","total":"0.01383692","tradeID":"1213556","type":"buy"}]}}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should place margin buy order with lending rate", func() {
+			marginOrder, err := client.MarginBuy("BTC_DASH", decimal.New(1, -2), decimal.New(1, 0), decimal.New(2, -2))
+			So(err, ShouldBeNil)
+
+			So(form["command"], ShouldEqual, "marginBuy")
+			So(form["lendingRate"], ShouldEqual, "0.02")
+			So(uint64(marginOrder.OrderNumber), ShouldEqual, 154407998)
+			So(marginOrder.ResultingTrades["BTC_DASH"][0].Rate.String(), ShouldEqual, "0.01383692")
+		})
+
+		Convey("Should place margin sell order without lending rate", func() {
+			_, err := client.MarginSell("BTC_DASH", decimal.New(1, -2), decimal.New(1, 0), decimal.Zero)
+			So(err, ShouldBeNil)
+
+			So(form["command"], ShouldEqual, "marginSell")
+			_, ok := form["lendingRate"]
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("Should return error on unsuccessful response", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"success":0,"message":"Margin order failed."}`)
+			}
+
+			_, err := client.MarginBuy("BTC_DASH", decimal.New(1, -2), decimal.New(1, 0), decimal.Zero)
+			So(err, ShouldBeError)
+		})
+	})
+}
+
+func TestClient_MarginPosition(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"amount":"40.94717831","total":"-0.09671314","basePrice":"0.00236190","liquidationPrice":-1,"pl":"-0.00058655","lendingFees":"-0.00000038","type":"long"}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return margin position", func() {
+			position, err := client.MarginPosition("BTC_DASH")
+			So(err, ShouldBeNil)
+
+			So(position.Type, ShouldEqual, MarginPositionLong)
+			So(position.BasePrice.String(), ShouldEqual, "0.0023619")
+			So(position.LiquidationPrice.String(), ShouldEqual, "-1")
+			So(position.PL.String(), ShouldEqual, "-0.00058655")
+			So(position.LendingFees.String(), ShouldEqual, "-0.00000038")
+		})
+
+		Convey("Should return all margin positions", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"BTC_DASH":{"amount":"0","total":"0","basePrice":"0","liquidationPrice":-1,"pl":"0","lendingFees":"0","type":"none"}}`)
+			}
+
+			positions, err := client.MarginPositionAll()
+			So(err, ShouldBeNil)
+			So(positions["BTC_DASH"].Type, ShouldEqual, MarginPositionNone)
+		})
+	})
+}
+
+func TestClient_CloseMarginPosition(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"success":1,"message":"Successfully closed margin position.","resultingTrades":{"BTC_XMR":[{"amount":"7.09215901","date":"2015-05-10 22:38:49","rate":"0.00235337","total":"0.01669047","tradeID":"1213346","type":"sell"}]}}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should close margin position", func() {
+			closedPosition, err := client.CloseMarginPosition("BTC_XMR")
+			So(err, ShouldBeNil)
+
+			So(closedPosition.Message, ShouldEqual, "Successfully closed margin position.")
+			So(len(closedPosition.ResultingTrades["BTC_XMR"]), ShouldEqual, 1)
+		})
+
+		Convey("Should return error on unsuccessful response", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"success":0,"message":"You do not have an open position in this market."}`)
+			}
+
+			_, err := client.CloseMarginPosition("BTC_XMR")
+			So(err, ShouldBeError)
+		})
+	})
+}
+
+func TestClient_MarginAccountSummary(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"totalValue":"0.00346561","pl":"-0.00001220","lendingFees":"0.00000000","netValue":"0.00345341","totalBorrowedValue":"0.00123220","currentMargin":"2.80263755"}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return margin account summary", func() {
+			summary, err := client.MarginAccountSummary()
+			So(err, ShouldBeNil)
+
+			So(summary.NetValue.String(), ShouldEqual, "0.00345341")
+			So(summary.CurrentMargin.String(), ShouldEqual, "2.80263755")
+		})
+	})
+}
+
+func TestClient_TradableBalances(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"BTC_DASH":{"BTC":"8.50274777","DASH":"654.05752077"},"BTC_LTC":{"BTC":"8.50274777","LTC":"1214.67825290"}}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return tradable balances", func() {
+			balances, err := client.TradableBalances()
+			So(err, ShouldBeNil)
+
+			So(len(balances), ShouldEqual, 2)
+			So(balances["BTC_DASH"]["DASH"].String(), ShouldEqual, "654.05752077")
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// ApiRequest is a single API call as seen by middleware. Params include the command and, for trading calls, the nonce.
+// The key secret and the request signature are never exposed, the signature is computed from Params after the chain.
+type ApiRequest struct {
+	Command string
+	Trading bool
+	Params  Params
+	// Key is the API key used to sign a trading call, empty for public calls
+	Key string
+}
+
+type ApiResponse struct {
+	StatusCode int
+	Body       []byte
+	// Duration is the time spent on the HTTP exchange
+	Duration time.Duration
+}
+
+// ErrNoResponse is returned when a round trip returns neither a response nor an error
+var ErrNoResponse = errors.New("middleware returned no response and no error")
+
+// RoundTrip performs an API call. A response is returned along with API errors, so middleware can inspect both.
+// A nil response must come with an error, roundTrip returns ErrNoResponse otherwise.
+type RoundTrip func(ctx context.Context, request *ApiRequest) (*ApiResponse, error)
+
+// Middleware wraps API calls for logging, metrics, auditing or fault injection.
+// It may change the request, return its own response or error, or skip next altogether.
+type Middleware func(next RoundTrip) RoundTrip
+
+// WithMiddleware appends middleware to the chain, the first one is the outermost
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(client *Client) {
+		client.middleware = append(client.middleware, middleware...)
+	}
+}
+
+// AddMiddleware appends middleware to the chain, it applies to calls started afterwards
+func (client *Client) AddMiddleware(middleware ...Middleware) {
+	client.middlewareMutex.Lock()
+	defer client.middlewareMutex.Unlock()
+
+	// Calls in flight keep the chain they started with
+	client.middleware = append(append([]Middleware{}, client.middleware...), middleware...)
+}
+
+// roundTrip passes the request through the middleware chain to send
+func (client *Client) roundTrip(ctx context.Context, request *ApiRequest, send RoundTrip) (*ApiResponse, error) {
+	client.middlewareMutex.RLock()
+	middleware := client.middleware
+	client.middlewareMutex.RUnlock()
+
+	roundTrip := send
+	for i := len(middleware) - 1; i >= 0; i-- {
+		roundTrip = middleware[i](roundTrip)
+	}
+
+	response, err := roundTrip(ctx, request)
+	if response == nil && err == nil {
+		return nil, ErrNoResponse
+	}
+
+	return response, err
+}
+
+// send is the innermost round trip, it performs the HTTP exchange and checks the response for API errors
+func (client *Client) send(ctx context.Context, command string, request *http.Request) (*ApiResponse, error) {
+	started := time.Now()
+	response, err := client.httpClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	apiResponse := &ApiResponse{
+		StatusCode: response.StatusCode,
+		Body:       body,
+		Duration:   time.Since(started),
+	}
+	if err != nil {
+		return apiResponse, err
+	}
+
+	return apiResponse, checkResponse(command, response.StatusCode, body)
+}
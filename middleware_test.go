@@ -0,0 +1,128 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClient_middleware(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var received []string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				received = append(received, r.Form.Get("command")+" "+r.Form.Get("currencyPair"))
+				if r.Form.Get("command") == "returnOpenOrders" {
+					fmt.Fprint(w, `{"error":"Invalid currency pair."}`)
+					return
+				}
+				fmt.Fprint(w, `{"BTC":"1.0"}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		var log []string
+		logging := func(name string) Middleware {
+			return func(next RoundTrip) RoundTrip {
+				return func(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
+					log = append(log, fmt.Sprintf("%s > %s trading=%t key=%s", name, request.Command, request.Trading, request.Key))
+					response, err := next(ctx, request)
+					if response != nil {
+						log = append(log, fmt.Sprintf("%s < %d %s err=%v", name, response.StatusCode, response.Body, err))
+					}
+					return response, err
+				}
+			}
+		}
+
+		client := NewClient([]Key{Key{"key", "secret"}}, WithMiddleware(logging("outer"), logging("inner")))
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+		client.SetRetryPolicy(RetryPolicy{})
+
+		Convey("Should pass calls through the chain in order", func() {
+			_, err := client.Balances()
+			So(err, ShouldBeNil)
+			So(log, ShouldResemble, []string{
+				"outer > returnBalances trading=true key=key",
+				"inner > returnBalances trading=true key=key",
+				`inner < 200 {"BTC":"1.0"} err=<nil>`,
+				`outer < 200 {"BTC":"1.0"} err=<nil>`,
+			})
+		})
+
+		Convey("Should expose API errors with the raw response", func() {
+			_, err := client.OpenOrders("BTC_XYZ")
+			So(errors.Is(err, ErrInvalidCurrencyPair), ShouldBeTrue)
+			So(log[2], ShouldEqual, `inner < 200 {"error":"Invalid currency pair."} err=returnOpenOrders: Invalid currency pair.`)
+		})
+
+		Convey("Should not expose the secret", func() {
+			var params Params
+			client.AddMiddleware(func(next RoundTrip) RoundTrip {
+				return func(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
+					params = request.Params
+					return next(ctx, request)
+				}
+			})
+
+			_, err := client.Balances()
+			So(err, ShouldBeNil)
+			So(params["command"], ShouldEqual, "returnBalances")
+			So(params["nonce"], ShouldNotBeEmpty)
+			for _, value := range params {
+				So(value, ShouldNotContainSubstring, "secret")
+			}
+		})
+
+		Convey("Should send requests changed by middleware", func() {
+			client.AddMiddleware(func(next RoundTrip) RoundTrip {
+				return func(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
+					request.Params["currencyPair"] = strings.ToUpper(request.Params["currencyPair"])
+					return next(ctx, request)
+				}
+			})
+
+			_, err := client.OpenOrders("btc_xyz")
+			So(err, ShouldNotBeNil)
+			So(received, ShouldResemble, []string{"returnOpenOrders BTC_XYZ"})
+		})
+
+		Convey("Should return injected faults without sending", func() {
+			client.AddMiddleware(func(next RoundTrip) RoundTrip {
+				return func(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
+					return nil, &APIError{Command: request.Command, Message: "injected", Kind: ErrorKindMaintenance}
+				}
+			})
+
+			_, err := client.Ticker()
+			So(errors.Is(err, ErrMaintenance), ShouldBeTrue)
+			So(received, ShouldBeEmpty)
+			So(log, ShouldResemble, []string{
+				"outer > returnTicker trading=false key=",
+				"inner > returnTicker trading=false key=",
+			})
+		})
+
+		Convey("Should fail without a response or an error", func() {
+			client.AddMiddleware(func(next RoundTrip) RoundTrip {
+				return func(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
+					return nil, nil
+				}
+			})
+
+			_, err := client.Ticker()
+			So(err, ShouldEqual, ErrNoResponse)
+			_, err = client.Balances()
+			So(err, ShouldEqual, ErrNoResponse)
+			So(received, ShouldBeEmpty)
+		})
+	})
+}
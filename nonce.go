@@ -0,0 +1,213 @@
+package poloniex
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var expectedNonceRegexp = regexp.MustCompile(`(?i)nonce must be greater than (\d+)`)
+
+// NonceSource provides nonces for trading API requests signed with the key
+type NonceSource interface {
+	Nonce(key string) (uint64, error)
+	// Resync makes every following nonce for the key greater than minimum
+	Resync(key string, minimum uint64) error
+}
+
+// MonotonicNonceSource issues strictly increasing nonces per key based on the current time in nanoseconds.
+// It never repeats a nonce even when the clock steps backwards or several nonces are requested
+// within the same nanosecond.
+type MonotonicNonceSource struct {
+	last sync.Map
+}
+
+func NewMonotonicNonceSource() *MonotonicNonceSource {
+	return &MonotonicNonceSource{}
+}
+
+func (source *MonotonicNonceSource) counter(key string) *uint64 {
+	counter, _ := source.last.LoadOrStore(key, new(uint64))
+	return counter.(*uint64)
+}
+
+func (source *MonotonicNonceSource) Nonce(key string) (uint64, error) {
+	counter := source.counter(key)
+	for {
+		last := atomic.LoadUint64(counter)
+		next := uint64(time.Now().UnixNano())
+		if next <= last {
+			next = last + 1
+		}
+
+		if atomic.CompareAndSwapUint64(counter, last, next) {
+			return next, nil
+		}
+	}
+}
+
+func (source *MonotonicNonceSource) Resync(key string, minimum uint64) error {
+	counter := source.counter(key)
+	for {
+		last := atomic.LoadUint64(counter)
+		if last >= minimum || atomic.CompareAndSwapUint64(counter, last, minimum) {
+			return nil
+		}
+	}
+}
+
+const defaultNonceReservation = time.Minute
+
+// FileNonceSource is a MonotonicNonceSource persisting a reserved high-water mark of every key to a file,
+// so nonces keep growing across restarts. The file is written only when a nonce crosses the mark of its key,
+// which then moves Reservation ahead of the nonce.
+type FileNonceSource struct {
+	// Reservation is how far ahead of issued nonces the mark is reserved, in nanoseconds like the nonces
+	Reservation time.Duration
+
+	monotonic *MonotonicNonceSource
+	path      string
+	reserved  sync.Map
+	mutex     sync.Mutex
+}
+
+func NewFileNonceSource(path string) (*FileNonceSource, error) {
+	source := &FileNonceSource{
+		Reservation: defaultNonceReservation,
+		monotonic:   NewMonotonicNonceSource(),
+		path:        path,
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return source, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]uint64{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	for key, value := range values {
+		source.monotonic.Resync(key, value)
+		source.mark(key, value)
+	}
+
+	return source, nil
+}
+
+func (source *FileNonceSource) mark(key string, value uint64) {
+	mark, _ := source.reserved.LoadOrStore(key, new(uint64))
+	atomic.StoreUint64(mark.(*uint64), value)
+}
+
+func (source *FileNonceSource) reservedMark(key string) uint64 {
+	mark, ok := source.reserved.Load(key)
+	if !ok {
+		return 0
+	}
+
+	return atomic.LoadUint64(mark.(*uint64))
+}
+
+func (source *FileNonceSource) Nonce(key string) (uint64, error) {
+	nonce, err := source.monotonic.Nonce(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if nonce <= source.reservedMark(key) {
+		return nonce, nil
+	}
+
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+
+	// Another caller may have moved the mark meanwhile
+	if nonce <= source.reservedMark(key) {
+		return nonce, nil
+	}
+
+	marks := source.marks()
+	marks[key] = nonce + uint64(source.Reservation)
+	if err := source.persist(marks); err != nil {
+		return 0, err
+	}
+	source.mark(key, marks[key])
+
+	return nonce, nil
+}
+
+// Resync needs no write, the following nonce is greater than minimum and crosses the mark when it is reached
+func (source *FileNonceSource) Resync(key string, minimum uint64) error {
+	return source.monotonic.Resync(key, minimum)
+}
+
+func (source *FileNonceSource) marks() map[string]uint64 {
+	values := map[string]uint64{}
+	source.reserved.Range(func(key, mark interface{}) bool {
+		values[key.(string)] = atomic.LoadUint64(mark.(*uint64))
+		return true
+	})
+
+	return values
+}
+
+func (source *FileNonceSource) persist(marks map[string]uint64) error {
+	data, err := json.Marshal(marks)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(source.path), filepath.Base(source.path))
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), source.path)
+}
+
+// expectedNonce extracts the nonce the exchange expects from an invalid nonce error message
+func expectedNonce(message string) (uint64, bool) {
+	matches := expectedNonceRegexp.FindStringSubmatch(message)
+	if matches == nil {
+		return 0, false
+	}
+
+	nonce, err := strconv.ParseUint(matches[1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return nonce, true
+}
+
+func (client *Client) SetNonceSource(source NonceSource) {
+	client.nonceSource = source
+}
@@ -0,0 +1,163 @@
+package poloniex
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMonotonicNonceSource(t *testing.T) {
+	Convey("Given monotonic nonce source", t, func() {
+		source := NewMonotonicNonceSource()
+
+		Convey("It should never repeat nonces for concurrent callers", func() {
+			const workers, perWorker = 8, 1000
+			nonces := make(chan uint64, workers*perWorker)
+
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < perWorker; j++ {
+						nonce, _ := source.Nonce("key")
+						nonces <- nonce
+					}
+				}()
+			}
+			wg.Wait()
+			close(nonces)
+
+			seen := map[uint64]bool{}
+			for nonce := range nonces {
+				seen[nonce] = true
+			}
+			So(len(seen), ShouldEqual, workers*perWorker)
+		})
+
+		Convey("It should issue nonces greater than resynced value", func() {
+			future := uint64(time.Now().Add(time.Hour).UnixNano())
+			So(source.Resync("key", future), ShouldBeNil)
+
+			nonce, err := source.Nonce("key")
+			So(err, ShouldBeNil)
+			So(nonce, ShouldBeGreaterThan, future)
+
+			other, err := source.Nonce("other")
+			So(err, ShouldBeNil)
+			So(other, ShouldBeLessThan, future)
+		})
+	})
+}
+
+func TestFileNonceSource(t *testing.T) {
+	Convey("Given file nonce source", t, func() {
+		dir, err := ioutil.TempDir("", "nonce")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "nonce.json")
+		source, err := NewFileNonceSource(path)
+		So(err, ShouldBeNil)
+
+		future := uint64(time.Now().Add(time.Hour).UnixNano())
+		So(source.Resync("key", future), ShouldBeNil)
+		last, err := source.Nonce("key")
+		So(err, ShouldBeNil)
+
+		Convey("It should continue after the persisted high-water mark", func() {
+			restored, err := NewFileNonceSource(path)
+			So(err, ShouldBeNil)
+
+			nonce, err := restored.Nonce("key")
+			So(err, ShouldBeNil)
+			So(nonce, ShouldBeGreaterThan, last)
+		})
+
+		Convey("It should write the file only when a nonce crosses the reserved mark", func() {
+			persisted, err := ioutil.ReadFile(path)
+			So(err, ShouldBeNil)
+
+			for i := 0; i < 100; i++ {
+				_, err := source.Nonce("key")
+				So(err, ShouldBeNil)
+			}
+			unchanged, err := ioutil.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(unchanged), ShouldEqual, string(persisted))
+
+			So(source.Resync("key", last+uint64(2*source.Reservation)), ShouldBeNil)
+			_, err = source.Nonce("key")
+			So(err, ShouldBeNil)
+			changed, err := ioutil.ReadFile(path)
+			So(err, ShouldBeNil)
+			So(string(changed), ShouldNotEqual, string(persisted))
+		})
+
+		Convey("It should fail on corrupted file", func() {
+			So(ioutil.WriteFile(path, []byte(`///`), 0600), ShouldBeNil)
+
+			_, err := NewFileNonceSource(path)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func Test_expectedNonce(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    uint64
+		wantOk  bool
+	}{
+		{"with expected nonce", "Nonce must be greater than 1506000000000000. You provided 1505000000000000.", 1506000000000000, true},
+		{"other message", "Invalid API key/secret pair.", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := expectedNonce(tt.message)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("expectedNonce() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestClient_resyncNonce(t *testing.T) {
+	Convey("Setup server expecting greater nonce", t, func() {
+		expected := uint64(time.Now().Add(time.Hour).UnixNano())
+		var lastNonce uint64
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				lastNonce, _ = strconv.ParseUint(r.PostForm.Get("nonce"), 10, 64)
+				if lastNonce <= expected {
+					fmt.Fprintf(w, `{"error":"Nonce must be greater than %d. You provided %d."}`, expected, lastNonce)
+					return
+				}
+				fmt.Fprint(w, `{"BTC":"0.59098578"}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2})
+
+		Convey("Should resync and succeed on retry", func() {
+			balances, err := client.Balances()
+			So(err, ShouldBeNil)
+			So(len(balances), ShouldEqual, 1)
+			So(lastNonce, ShouldBeGreaterThan, expected)
+		})
+	})
+}
@@ -0,0 +1,303 @@
+package poloniex
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	orderBookStreamBuffer             = 1024
+	defaultOrderBookRetryInitialDelay = time.Second
+	defaultOrderBookRetryMaxDelay     = 30 * time.Second
+)
+
+type orderBookSnapshot struct {
+	orderBook OrderBook
+	err       error
+}
+
+// OrderBookManager maintains the order book of a pair from a snapshot and the WAMP stream of the pair.
+// Events arriving while the snapshot is fetched are buffered, those already included in the snapshot are discarded.
+// The snapshot is fetched again whenever events are missing or out of order, and after SnapshotRetry backoff
+// when it fails or does not join the buffered events.
+type OrderBookManager struct {
+	client     *Client
+	wampClient *WampClient
+	pair       string
+
+	// SnapshotRetry delays fetching the snapshot again after a failure
+	SnapshotRetry RetryPolicy
+
+	mutex            sync.RWMutex
+	asks             map[string]Order
+	bids             map[string]Order
+	snapshotSequence uint
+	sequence         uint
+	synced           bool
+	changes          chan struct{}
+}
+
+func NewOrderBookManager(client *Client, wampClient *WampClient, pair string) *OrderBookManager {
+	return &OrderBookManager{
+		client:     client,
+		wampClient: wampClient,
+		pair:       pair,
+		SnapshotRetry: RetryPolicy{
+			InitialBackoff: defaultOrderBookRetryInitialDelay,
+			MaxBackoff:     defaultOrderBookRetryMaxDelay,
+		},
+		asks:    make(map[string]Order),
+		bids:    make(map[string]Order),
+		changes: make(chan struct{}, 1),
+	}
+}
+
+// Run subscribes to the pair and maintains the order book until ctx is done, then returns ctx.Err().
+// Stream errors and failed snapshots are reported to errChan, which may be nil.
+func (manager *OrderBookManager) Run(ctx context.Context, errChan chan<- error) error {
+	messageChan := make(chan interface{}, orderBookStreamBuffer)
+	streamErrChan := make(chan error, orderBookStreamBuffer)
+
+	if err := manager.wampClient.SubscribeToPair(manager.pair, messageChan, streamErrChan); err != nil {
+		return err
+	}
+	defer manager.wampClient.UnsubscribeFromPair(manager.pair)
+
+	snapshots := make(chan orderBookSnapshot, 1)
+	attempt := 0
+	fetch := func() {
+		attempt++
+		go func() {
+			orderBook, err := manager.client.OrderBookCtx(ctx, manager.pair)
+			snapshots <- orderBookSnapshot{orderBook: orderBook, err: err}
+		}()
+	}
+
+	var buffered []interface{}
+	var retry <-chan time.Time
+	syncing := true
+	fetch()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case err := <-streamErrChan:
+			manager.report(ctx, errChan, err)
+
+		case <-retry:
+			retry = nil
+			fetch()
+
+		case snapshot := <-snapshots:
+			if snapshot.err != nil {
+				manager.report(ctx, errChan, snapshot.err)
+				retry = time.After(manager.SnapshotRetry.backoff(attempt))
+				continue
+			}
+
+			// The buffered events are kept for the next snapshot until one joins them
+			if !manager.install(snapshot.orderBook, buffered) {
+				// The snapshot trails the stream, fetching it again at once would only spend the request budget
+				retry = time.After(manager.SnapshotRetry.backoff(attempt))
+				continue
+			}
+			buffered = nil
+			attempt = 0
+			syncing = false
+
+		case message := <-messageChan:
+			if syncing {
+				buffered = append(buffered, message)
+				continue
+			}
+
+			if !manager.applyLocked(message) {
+				syncing = true
+				fetch()
+			}
+		}
+	}
+}
+
+func (manager *OrderBookManager) report(ctx context.Context, errChan chan<- error, err error) {
+	if errChan == nil {
+		return
+	}
+
+	select {
+	case errChan <- err:
+	case <-ctx.Done():
+	}
+}
+
+// install replaces the book with the snapshot and applies the buffered messages following it
+func (manager *OrderBookManager) install(orderBook OrderBook, buffered []interface{}) bool {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.asks = make(map[string]Order, len(orderBook.Asks))
+	for _, order := range orderBook.Asks {
+		manager.asks[order.Rate.String()] = order
+	}
+
+	manager.bids = make(map[string]Order, len(orderBook.Bids))
+	for _, order := range orderBook.Bids {
+		manager.bids[order.Rate.String()] = order
+	}
+
+	manager.snapshotSequence = orderBook.Sequence
+	manager.sequence = orderBook.Sequence
+	manager.synced = true
+
+	for _, message := range buffered {
+		if !manager.apply(message) {
+			manager.synced = false
+			return false
+		}
+	}
+
+	manager.notify()
+	return true
+}
+
+func (manager *OrderBookManager) applyLocked(message interface{}) bool {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if !manager.apply(message) {
+		manager.synced = false
+		return false
+	}
+
+	manager.notify()
+	return true
+}
+
+// apply updates the book with the stream message and reports whether the book is still consistent
+func (manager *OrderBookManager) apply(message interface{}) bool {
+	switch message := message.(type) {
+	case SequenceGap:
+		// Late events are checked on arrival, missing ones matter only after the snapshot
+		return message.Got < message.Expected || message.Got <= manager.snapshotSequence+1
+
+	case NewTrade:
+		return manager.advance(message.Sequence)
+
+	case OrderModification:
+		if message.Sequence <= manager.snapshotSequence {
+			return true
+		}
+
+		if !manager.advance(message.Sequence) {
+			return false
+		}
+
+		orders := manager.bids
+		if message.Type == OrderUpdateTypeAsk {
+			orders = manager.asks
+		}
+
+		// Removals carry no amount
+		if message.Amount.Equal(decimal.Zero) {
+			delete(orders, message.Rate.String())
+		} else {
+			orders[message.Rate.String()] = message.Order
+		}
+	}
+
+	return true
+}
+
+// advance moves to the event sequence, events of one sequence may carry several messages
+func (manager *OrderBookManager) advance(sequence uint) bool {
+	if sequence <= manager.snapshotSequence {
+		return true
+	}
+
+	if sequence < manager.sequence || sequence > manager.sequence+1 {
+		return false
+	}
+
+	manager.sequence = sequence
+	return true
+}
+
+func (manager *OrderBookManager) notify() {
+	select {
+	case manager.changes <- struct{}{}:
+	default:
+	}
+}
+
+// Changes receives a value after the book changes, changes made before the value is received are coalesced
+func (manager *OrderBookManager) Changes() <-chan struct{} {
+	return manager.changes
+}
+
+// Synced reports whether the book is consistent with the stream, it is not while a snapshot is fetched
+func (manager *OrderBookManager) Synced() bool {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	return manager.synced
+}
+
+// Sequence returns the sequence of the last event applied to the book
+func (manager *OrderBookManager) Sequence() uint {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	return manager.sequence
+}
+
+func (manager *OrderBookManager) BestAsk() (order Order, ok bool) {
+	asks, _ := manager.Depth(1)
+	if len(asks) == 0 {
+		return order, false
+	}
+
+	return asks[0], true
+}
+
+func (manager *OrderBookManager) BestBid() (order Order, ok bool) {
+	_, bids := manager.Depth(1)
+	if len(bids) == 0 {
+		return order, false
+	}
+
+	return bids[0], true
+}
+
+// Depth returns up to n best asks by ascending rate and bids by descending rate, all of them when n is not positive
+func (manager *OrderBookManager) Depth(n int) (asks, bids []Order) {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	asks = sortedOrders(manager.asks, n, func(a, b Order) bool { return a.Rate.LessThan(b.Rate) })
+	bids = sortedOrders(manager.bids, n, func(a, b Order) bool { return a.Rate.GreaterThan(b.Rate) })
+
+	return asks, bids
+}
+
+func sortedOrders(orders map[string]Order, n int, less func(a, b Order) bool) []Order {
+	sorted := make([]Order, 0, len(orders))
+	for _, order := range orders {
+		sorted = append(sorted, order)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+
+	if n > 0 && len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	return sorted
+}
@@ -0,0 +1,218 @@
+package poloniex
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOrderBookManager(t *testing.T) {
+	order := func(rate, amount int64) Order {
+		order := Order{Rate: decimal.New(rate, -3), Amount: decimal.New(amount, 0)}
+		order.CalculateTotal()
+		return order
+	}
+	modification := func(sequence uint, orderType string, rate, amount int64) OrderModification {
+		return OrderModification{Type: orderType, Sequence: sequence, Order: order(rate, amount)}
+	}
+
+	Convey("Given manager with snapshot at sequence 10", t, func() {
+		manager := NewOrderBookManager(nil, nil, "BTC_ETH")
+		snapshot := OrderBook{
+			Asks:     []Order{order(120, 1), order(110, 2), order(130, 3)},
+			Bids:     []Order{order(90, 1), order(100, 2), order(80, 3)},
+			Sequence: 10,
+		}
+
+		Convey("It should discard buffered events included in the snapshot", func() {
+			ok := manager.install(snapshot, []interface{}{
+				modification(9, OrderUpdateTypeAsk, 110, 0),
+				modification(10, OrderUpdateTypeAsk, 110, 0),
+				modification(11, OrderUpdateTypeBid, 100, 0),
+				modification(11, OrderUpdateTypeBid, 95, 4),
+			})
+			So(ok, ShouldBeTrue)
+			So(manager.Synced(), ShouldBeTrue)
+			So(manager.Sequence(), ShouldEqual, 11)
+
+			bestAsk, ok := manager.BestAsk()
+			So(ok, ShouldBeTrue)
+			So(bestAsk.Rate.String(), ShouldEqual, "0.11")
+
+			bestBid, ok := manager.BestBid()
+			So(ok, ShouldBeTrue)
+			So(bestBid.Rate.String(), ShouldEqual, "0.095")
+			So(bestBid.Total.String(), ShouldEqual, "0.38")
+
+			asks, bids := manager.Depth(2)
+			So(asks, ShouldResemble, []Order{order(110, 2), order(120, 1)})
+			So(bids, ShouldHaveLength, 2)
+			So(bids[1].Rate.String(), ShouldEqual, "0.09")
+
+			So(manager.Changes(), ShouldHaveLength, 1)
+		})
+
+		Convey("It should fail on missing buffered events", func() {
+			ok := manager.install(snapshot, []interface{}{modification(12, OrderUpdateTypeAsk, 110, 0)})
+			So(ok, ShouldBeFalse)
+			So(manager.Synced(), ShouldBeFalse)
+		})
+
+		Convey("It should ignore gaps before the snapshot", func() {
+			ok := manager.install(snapshot, []interface{}{
+				SequenceGap{Pair: "BTC_ETH", Expected: 5, Got: 9},
+				modification(11, OrderUpdateTypeAsk, 110, 0),
+			})
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("When synced", func() {
+			So(manager.install(snapshot, nil), ShouldBeTrue)
+			<-manager.Changes()
+
+			Convey("It should apply events in order", func() {
+				So(manager.applyLocked(modification(11, OrderUpdateTypeAsk, 110, 0)), ShouldBeTrue)
+				So(manager.applyLocked(NewTrade{Sequence: 12}), ShouldBeTrue)
+				So(manager.applyLocked(modification(12, OrderUpdateTypeAsk, 105, 1)), ShouldBeTrue)
+				So(manager.Changes(), ShouldHaveLength, 1)
+
+				asks, _ := manager.Depth(0)
+				So(asks, ShouldResemble, []Order{order(105, 1), order(120, 1), order(130, 3)})
+			})
+
+			Convey("It should fail on gaps after the snapshot", func() {
+				So(manager.applyLocked(SequenceGap{Pair: "BTC_ETH", Expected: 11, Got: 13}), ShouldBeFalse)
+				So(manager.Synced(), ShouldBeFalse)
+			})
+
+			Convey("It should fail on skipped and late events", func() {
+				So(manager.applyLocked(modification(12, OrderUpdateTypeAsk, 110, 0)), ShouldBeFalse)
+
+				So(manager.install(snapshot, nil), ShouldBeTrue)
+				So(manager.applyLocked(modification(12, OrderUpdateTypeAsk, 110, 0)), ShouldBeFalse)
+				So(manager.install(snapshot, []interface{}{modification(11, OrderUpdateTypeAsk, 110, 0), modification(12, OrderUpdateTypeAsk, 120, 0)}), ShouldBeTrue)
+				So(manager.applyLocked(modification(11, OrderUpdateTypeAsk, 130, 0)), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestOrderBookManager_Run(t *testing.T) {
+	Convey("Given fake WAMP server and order book server", t, func() {
+		wampServer, wampHTTPServer, clear := newTestWebsocketServer(t)
+		defer clear()
+
+		localClient, err := wampServer.GetLocalClient(wampRealm, nil)
+		if err != nil {
+			t.Fatalf("error getting local client: %s", err)
+		}
+
+		wampClient, err := NewWampClient(&tls.Config{InsecureSkipVerify: true}, nil,
+			WithWampEndpoint(strings.Replace(wampHTTPServer.URL, "https://", "wss://", 1)))
+		if err != nil {
+			t.Fatalf("error creating client: %s", err)
+		}
+		defer wampClient.Close()
+
+		snapshots := make(chan string)
+		requested := make(chan struct{}, 10)
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("command") != "returnOrderBook" {
+					return
+				}
+				requested <- struct{}{}
+				select {
+				case snapshot := <-snapshots:
+					fmt.Fprint(w, snapshot)
+				case <-r.Context().Done():
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		manager := NewOrderBookManager(client, wampClient, "BTC_ETH")
+		manager.SnapshotRetry = RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+		publish := func(sequence float64, orderType, rate, amount string) {
+			message := map[string]interface{}{
+				"type": messageTypeOrderBookModify,
+				"data": map[string]interface{}{"type": orderType, "rate": rate, "amount": amount},
+			}
+			if err := localClient.Publish("BTC_ETH", nil, []interface{}{message}, map[string]interface{}{"seq": sequence}); err != nil {
+				t.Fatalf("error publishing to pair: %s", err)
+			}
+		}
+
+		waitFor := func(condition func() bool) bool {
+			deadline := time.After(5 * time.Second)
+			for !condition() {
+				select {
+				case <-manager.Changes():
+				case <-time.After(10 * time.Millisecond):
+				case <-deadline:
+					return false
+				}
+			}
+			return true
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- manager.Run(ctx, nil)
+		}()
+		defer func() {
+			cancel()
+			<-done
+		}()
+
+		Convey("It should sync from snapshot and stream and fetch a new snapshot after a gap", func() {
+			<-requested
+
+			// Events arriving while the snapshot is fetched, 9 and 10 are included in the snapshot
+			publish(9, OrderUpdateTypeAsk, "0.15000000", "5.00000000")
+			publish(10, OrderUpdateTypeAsk, "0.15000000", "0.00000000")
+			publish(11, OrderUpdateTypeBid, "0.10500000", "4.00000000")
+			time.Sleep(100 * time.Millisecond)
+
+			// A snapshot trailing the buffered events is fetched again after backoff
+			snapshots <- `{"asks":[[0.11,2]],"bids":[[0.1,2]],"isFrozen":0,"seq":7}`
+			<-requested
+			So(manager.Synced(), ShouldBeFalse)
+			snapshots <- `{"asks":[[0.11,2],[0.12,1]],"bids":[[0.1,2]],"isFrozen":0,"seq":10}`
+
+			So(waitFor(func() bool { return manager.Synced() && manager.Sequence() == 11 }), ShouldBeTrue)
+			asks, bids := manager.Depth(0)
+			So(asks, ShouldHaveLength, 2)
+			So(asks[0].Rate.String(), ShouldEqual, "0.11")
+			So(asks[1].Rate.String(), ShouldEqual, "0.12")
+			So(bids, ShouldHaveLength, 2)
+			So(bids[0].Rate.String(), ShouldEqual, "0.105")
+
+			// 12 is missing
+			publish(13, OrderUpdateTypeAsk, "0.10800000", "1.00000000")
+			<-requested
+			So(waitFor(func() bool { return !manager.Synced() }), ShouldBeTrue)
+			snapshots <- `{"asks":[[0.11,2]],"bids":[[0.105,4]],"isFrozen":0,"seq":12}`
+
+			So(waitFor(func() bool { return manager.Synced() && manager.Sequence() == 13 }), ShouldBeTrue)
+			bestAsk, ok := manager.BestAsk()
+			So(ok, ShouldBeTrue)
+			So(bestAsk.Rate.String(), ShouldEqual, "0.108")
+			So(requested, ShouldBeEmpty)
+		})
+	})
+}
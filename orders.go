@@ -0,0 +1,224 @@
+package poloniex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// OrderState is the state of an order. OrderStatus reports only open, partially filled and closed orders:
+// the exchange keeps no record telling filled orders from cancelled ones, so OrderStateFilled and
+// OrderStateCancelled are never reported.
+type OrderState int
+
+const (
+	OrderStateOpen OrderState = iota
+	OrderStatePartiallyFilled
+	// OrderStateFilled is not reported by OrderStatus, filled orders are reported as OrderStateClosed
+	OrderStateFilled
+	// OrderStateCancelled is not reported by OrderStatus, cancelled orders are reported as OrderStateClosed
+	// when they have trades and as ErrOrderNotFound otherwise
+	OrderStateCancelled
+	// OrderStateClosed is an order with trades which is not open anymore, it is either filled or cancelled after a partial fill
+	OrderStateClosed
+)
+
+func (state OrderState) String() string {
+	switch state {
+	case OrderStateOpen:
+		return "open"
+	case OrderStatePartiallyFilled:
+		return "partially filled"
+	case OrderStateFilled:
+		return "filled"
+	case OrderStateCancelled:
+		return "cancelled"
+	case OrderStateClosed:
+		return "closed"
+	}
+
+	return fmt.Sprintf("OrderState(%d)", int(state))
+}
+
+type OrderStatus struct {
+	OrderNumber    uint64
+	State          OrderState
+	CurrencyPair   string
+	Type           string
+	Rate           decimal.Decimal
+	StartingAmount decimal.Decimal
+	FilledAmount   decimal.Decimal
+}
+
+type openOrderStatus struct {
+	CurrencyPair   string          `json:"currencyPair"`
+	Type           string          `json:"type"`
+	Rate           decimal.Decimal `json:"rate"`
+	Amount         decimal.Decimal `json:"amount"`
+	StartingAmount decimal.Decimal `json:"startingAmount"`
+}
+
+// OrderStatus reports whether an order is open, partially filled or closed. It can not report filled or cancelled
+// orders, since the exchange keeps no record of closed orders telling them apart.
+// Open orders are looked up with returnOrderStatus, closed ones are reconstructed from their trades.
+// A closed order has only CurrencyPair, Type and FilledAmount set, its starting amount and rate are unknown.
+// ErrOrderNotFound is returned for an order which is neither open nor traded, a cancelled order without trades
+// can not be told apart from a missing one.
+func (client *Client) OrderStatus(orderNumber uint64) (status OrderStatus, err error) {
+	return client.OrderStatusCtx(context.Background(), orderNumber)
+}
+
+func (client *Client) OrderStatusCtx(ctx context.Context, orderNumber uint64) (status OrderStatus, err error) {
+	status.OrderNumber = orderNumber
+
+	result := struct {
+		Success convertibleBool
+		Result  json.RawMessage
+	}{}
+
+	err = client.tradingApiRequest(ctx, &result, "returnOrderStatus",
+		Params{"orderNumber": fmt.Sprintf("%d", orderNumber)})
+	if err != nil {
+		return status, err
+	}
+
+	if result.Success {
+		orders := map[string]openOrderStatus{}
+		if err = json.Unmarshal(result.Result, &orders); err != nil {
+			return status, err
+		}
+
+		order, ok := orders[fmt.Sprintf("%d", orderNumber)]
+		if !ok {
+			return status, fmt.Errorf("returnOrderStatus response has no order %d", orderNumber)
+		}
+
+		status.CurrencyPair = order.CurrencyPair
+		status.Type = order.Type
+		status.Rate = order.Rate
+		status.StartingAmount = order.StartingAmount
+		status.FilledAmount = order.StartingAmount.Sub(order.Amount)
+		status.State = OrderStateOpen
+		if status.FilledAmount.GreaterThan(decimal.Zero) {
+			status.State = OrderStatePartiallyFilled
+		}
+
+		return status, nil
+	}
+
+	trades, err := client.OrderTradesCtx(ctx, orderNumber)
+	if err != nil && !errors.Is(err, ErrOrderNotFound) {
+		return status, err
+	}
+
+	if len(trades) == 0 {
+		return status, fmt.Errorf("%w: order %d is not open and has no trades", ErrOrderNotFound, orderNumber)
+	}
+
+	status.State = OrderStateClosed
+	for _, trade := range trades {
+		status.CurrencyPair = trade.CurrencyPair
+		status.Type = trade.Type
+		status.FilledAmount = status.FilledAmount.Add(trade.Amount)
+	}
+
+	return status, nil
+}
+
+type CancelResult struct {
+	OrderNumber uint64
+	Err         error
+}
+
+// CancelAllOrders cancels open orders of the currency pair, or of every pair when currencyPair is "all".
+// It uses the cancelAllOrders command and falls back to cancelling open orders one by one
+// when the exchange does not support it.
+func (client *Client) CancelAllOrders(currencyPair string) (results []CancelResult, err error) {
+	return client.CancelAllOrdersCtx(context.Background(), currencyPair)
+}
+
+func (client *Client) CancelAllOrdersCtx(ctx context.Context, currencyPair string) (results []CancelResult, err error) {
+	result := struct {
+		Success      convertibleBool
+		Message      string
+		OrderNumbers []uint64
+	}{}
+
+	params := Params{}
+	if currencyPair != "all" {
+		params["currencyPair"] = currencyPair
+	}
+
+	err = client.tradingApiRequest(ctx, &result, "cancelAllOrders", params)
+	if isInvalidCommand(err) {
+		return client.cancelOpenOrders(ctx, currencyPair)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Success {
+		return nil, fmt.Errorf("cancelAllOrders for currency pair %s is not successful: %s", currencyPair, result.Message)
+	}
+
+	for _, orderNumber := range result.OrderNumbers {
+		results = append(results, CancelResult{OrderNumber: orderNumber})
+	}
+
+	return results, nil
+}
+
+func (client *Client) cancelOpenOrders(ctx context.Context, currencyPair string) (results []CancelResult, err error) {
+	var orderNumbers []uint64
+	if currencyPair == "all" {
+		ordersAll, err := client.OpenOrdersAllCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, orders := range ordersAll {
+			for _, order := range orders {
+				orderNumbers = append(orderNumbers, order.OrderNumber)
+			}
+		}
+	} else {
+		orders, err := client.OpenOrdersCtx(ctx, currencyPair)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, order := range orders {
+			orderNumbers = append(orderNumbers, order.OrderNumber)
+		}
+	}
+
+	results = make([]CancelResult, len(orderNumbers))
+
+	var wg sync.WaitGroup
+	for i, orderNumber := range orderNumbers {
+		wg.Add(1)
+		go func(i int, orderNumber uint64) {
+			defer wg.Done()
+
+			results[i].OrderNumber = orderNumber
+			success, err := client.CancelOrderCtx(ctx, orderNumber)
+			if err == nil && !success {
+				err = fmt.Errorf("cancelOrder for order %d is not successful", orderNumber)
+			}
+			results[i].Err = err
+		}(i, orderNumber)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+func isInvalidCommand(err error) bool {
+	var apiError *APIError
+	return errors.As(err, &apiError) && strings.Contains(strings.ToLower(apiError.Message), "invalid command")
+}
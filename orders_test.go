@@ -0,0 +1,133 @@
+package poloniex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestClient_OrderStatus(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		orderStatusResponse := `{"result":{"6071071":{"status":"Partially filled","rate":"0.40000000","amount":"0.60000000","currencyPair":"BTC_ETH","date":"2018-10-17 17:04:50","total":"0.24000000","type":"buy","startingAmount":"1.00000000"}},"success":1}`
+		orderTradesResponse := `[]`
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				switch r.PostForm.Get("command") {
+				case "returnOrderStatus":
+					fmt.Fprint(w, orderStatusResponse)
+				case "returnOrderTrades":
+					fmt.Fprint(w, orderTradesResponse)
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		Convey("Should return partially filled open order", func() {
+			status, err := client.OrderStatus(6071071)
+			So(err, ShouldBeNil)
+
+			So(status.State, ShouldEqual, OrderStatePartiallyFilled)
+			So(status.CurrencyPair, ShouldEqual, "BTC_ETH")
+			So(status.FilledAmount.String(), ShouldEqual, "0.4")
+		})
+
+		Convey("Should return closed order from trades", func() {
+			orderStatusResponse = `{"result":{"error":"Order not found, or you are not the person who placed it."},"success":0}`
+			orderTradesResponse = `[{"globalTradeID":20825863,"tradeID":147142,"currencyPair":"BTC_ETH","type":"buy","rate":"0.40000000","amount":"0.75000000","total":"0.3","fee":"0.00200000","date":"2016-03-14 01:04:36"},
+{"globalTradeID":20825864,"tradeID":147143,"currencyPair":"BTC_ETH","type":"buy","rate":"0.40000000","amount":"0.25000000","total":"0.1","fee":"0.00200000","date":"2016-03-14 01:04:36"}]`
+
+			status, err := client.OrderStatus(6071071)
+			So(err, ShouldBeNil)
+
+			So(status.State, ShouldEqual, OrderStateClosed)
+			So(status.FilledAmount.String(), ShouldEqual, "1")
+			So(status.Type, ShouldEqual, TypeBuy)
+			So(status.StartingAmount.String(), ShouldEqual, "0")
+		})
+
+		Convey("Should return order not found without trades", func() {
+			orderStatusResponse = `{"result":{"error":"Order not found, or you are not the person who placed it."},"success":0}`
+			orderTradesResponse = `{"error":"Order not found, or you are not the person who placed it."}`
+
+			_, err := client.OrderStatus(6071071)
+			So(errors.Is(err, ErrOrderNotFound), ShouldBeTrue)
+
+			orderTradesResponse = `[]`
+			_, err = client.OrderStatus(6071071)
+			So(errors.Is(err, ErrOrderNotFound), ShouldBeTrue)
+		})
+	})
+}
+
+func TestClient_CancelAllOrders(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var mutex sync.Mutex
+		var cancelled []string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				switch r.PostForm.Get("command") {
+				case "cancelAllOrders":
+					fmt.Fprint(w, `{"success":1,"message":"Orders canceled","orderNumbers":[503749,888321]}`)
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		Convey("Should cancel all orders with cancelAllOrders", func() {
+			results, err := client.CancelAllOrders("BTC_ETH")
+			So(err, ShouldBeNil)
+
+			So(len(results), ShouldEqual, 2)
+			So(results[1].OrderNumber, ShouldEqual, 888321)
+			So(results[1].Err, ShouldBeNil)
+		})
+
+		Convey("Should fall back to cancelling open orders one by one", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				switch r.PostForm.Get("command") {
+				case "cancelAllOrders":
+					fmt.Fprint(w, `{"error":"Invalid command."}`)
+				case "returnOpenOrders":
+					fmt.Fprint(w, `[{"orderNumber":"120466","type":"sell","rate":"0.025","amount":"100","total":"2.5"},{"orderNumber":"120467","type":"sell","rate":"0.04","amount":"100","total":"4"}]`)
+				case "cancelOrder":
+					mutex.Lock()
+					cancelled = append(cancelled, r.PostForm.Get("orderNumber"))
+					mutex.Unlock()
+					if r.PostForm.Get("orderNumber") == "120467" {
+						fmt.Fprint(w, `{"error":"Invalid order number, or you are not the person who placed the order."}`)
+						return
+					}
+					fmt.Fprint(w, `{"success":1}`)
+				}
+			}
+
+			results, err := client.CancelAllOrders("BTC_ETH")
+			So(err, ShouldBeNil)
+
+			sort.Strings(cancelled)
+			So(cancelled, ShouldResemble, []string{"120466", "120467"})
+			So(len(results), ShouldEqual, 2)
+			So(results[0].OrderNumber, ShouldEqual, 120466)
+			So(results[0].Err, ShouldBeNil)
+			So(results[1].Err, ShouldNotBeNil)
+		})
+	})
+}
@@ -1,5 +1,7 @@
 package poloniex
 
+import "context"
+
 type keyPool struct {
 	keys chan *Key
 }
@@ -11,3 +13,12 @@ func (keyPool keyPool) Put(key *Key) {
 func (keyPool keyPool) Get() *Key {
 	return <-keyPool.keys
 }
+
+func (keyPool keyPool) GetCtx(ctx context.Context) (*Key, error) {
+	select {
+	case key := <-keyPool.keys:
+		return key, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
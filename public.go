@@ -4,17 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
 
 const (
-	publicApiEndpoint = "https://poloniex.com/public"
-
 	// Order unmarshalling constants
 	orderParamsCount = 2
 	orderRateIndex   = 0
 	orderAmountIndex = 1
+
+	volumeTotalPrefix = "total"
 )
 
 type Market struct {
@@ -33,10 +39,121 @@ type Market struct {
 type Ticker map[string]Market
 
 func (client *Client) Ticker() (ticker Ticker, err error) {
-	err = client.publicApiRequest(&ticker, "returnTicker")
+	return client.TickerCtx(context.Background())
+}
+
+func (client *Client) TickerCtx(ctx context.Context) (ticker Ticker, err error) {
+	err = client.publicApiRequest(ctx, &ticker, "returnTicker")
+	return
+}
+
+type PairVolume struct {
+	Base  decimal.Decimal
+	Quote decimal.Decimal
+}
+
+type Volume24h struct {
+	Pairs map[string]PairVolume
+	// Totals are keyed by currency, e.g. "BTC" for "totalBTC"
+	Totals map[string]decimal.Decimal
+}
+
+func (volume *Volume24h) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	volume.Pairs = make(map[string]PairVolume)
+	volume.Totals = make(map[string]decimal.Decimal)
+
+	for name, value := range fields {
+		if strings.HasPrefix(name, volumeTotalPrefix) {
+			var total decimal.Decimal
+			if err := json.Unmarshal(value, &total); err != nil {
+				return fmt.Errorf("volume %s: %s", name, err)
+			}
+
+			volume.Totals[strings.TrimPrefix(name, volumeTotalPrefix)] = total
+			continue
+		}
+
+		currencies := strings.SplitN(name, "_", 2)
+		if len(currencies) != 2 {
+			return fmt.Errorf("unexpected volume key %s", name)
+		}
+
+		var amounts map[string]decimal.Decimal
+		if err := json.Unmarshal(value, &amounts); err != nil {
+			return fmt.Errorf("volume %s: %s", name, err)
+		}
+
+		volume.Pairs[name] = PairVolume{
+			Base:  amounts[currencies[0]],
+			Quote: amounts[currencies[1]],
+		}
+	}
+
+	return nil
+}
+
+func (client *Client) Volume24h() (volume Volume24h, err error) {
+	return client.Volume24hCtx(context.Background())
+}
+
+func (client *Client) Volume24hCtx(ctx context.Context) (volume Volume24h, err error) {
+	err = client.publicApiRequest(ctx, &volume, "return24hVolume")
 	return
 }
 
+type RankedMarket struct {
+	CurrencyPair string
+	Market
+	Volume PairVolume
+}
+
+// MarketsByVolume returns markets ordered by 24h volume in the base currency, most liquid first.
+// Volumes in different base currencies are not comparable, so baseCurrency restricts
+// the result to pairs like "BTC_*"; an empty baseCurrency ranks all markets.
+func (client *Client) MarketsByVolume(baseCurrency string) (markets []RankedMarket, err error) {
+	return client.MarketsByVolumeCtx(context.Background(), baseCurrency)
+}
+
+func (client *Client) MarketsByVolumeCtx(ctx context.Context, baseCurrency string) (markets []RankedMarket, err error) {
+	ticker, err := client.TickerCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	volume, err := client.Volume24hCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for pair, market := range ticker {
+		if baseCurrency != "" && !strings.HasPrefix(pair, baseCurrency+"_") {
+			continue
+		}
+
+		pairVolume, ok := volume.Pairs[pair]
+		if !ok {
+			continue
+		}
+
+		markets = append(markets, RankedMarket{
+			CurrencyPair: pair,
+			Market:       market,
+			Volume:       pairVolume,
+		})
+	}
+
+	sort.Slice(markets, func(i, j int) bool {
+		return markets[i].Volume.Base.GreaterThan(markets[j].Volume.Base)
+	})
+
+	return markets, nil
+}
+
 type Order struct {
 	Rate   decimal.Decimal
 	Amount decimal.Decimal
@@ -73,7 +190,11 @@ type OrderBook struct {
 }
 
 func (client *Client) OrderBook(currencyPair string) (orderBook OrderBook, err error) {
-	err = client.publicApiRequest(&orderBook, "returnOrderBook", Params{
+	return client.OrderBookCtx(context.Background(), currencyPair)
+}
+
+func (client *Client) OrderBookCtx(ctx context.Context, currencyPair string) (orderBook OrderBook, err error) {
+	err = client.publicApiRequest(ctx, &orderBook, "returnOrderBook", Params{
 		"currencyPair": currencyPair,
 	})
 
@@ -81,7 +202,11 @@ func (client *Client) OrderBook(currencyPair string) (orderBook OrderBook, err e
 }
 
 func (client *Client) OrderBookAll() (orderBooks map[string]OrderBook, err error) {
-	err = client.publicApiRequest(&orderBooks, "returnOrderBook", Params{
+	return client.OrderBookAllCtx(context.Background())
+}
+
+func (client *Client) OrderBookAllCtx(ctx context.Context) (orderBooks map[string]OrderBook, err error) {
+	err = client.publicApiRequest(ctx, &orderBooks, "returnOrderBook", Params{
 		"currencyPair": "all",
 	})
 
@@ -89,7 +214,11 @@ func (client *Client) OrderBookAll() (orderBooks map[string]OrderBook, err error
 }
 
 func (client *Client) Currencies() (currencies map[string]Currency, err error) {
-	err = client.publicApiRequest(&currencies, "returnCurrencies", Params{})
+	return client.CurrenciesCtx(context.Background())
+}
+
+func (client *Client) CurrenciesCtx(ctx context.Context) (currencies map[string]Currency, err error) {
+	err = client.publicApiRequest(ctx, &currencies, "returnCurrencies", Params{})
 
 	return currencies, err
 }
@@ -106,7 +235,173 @@ type Currency struct {
 	Delisted           convertibleBool `json:"delisted"`
 }
 
-func (client *Client) publicApiRequest(result interface{}, method string, params ...Params) error {
+// Public trade history is limited to a window of one month and a number of trades per request
+var (
+	marketTradeHistoryMaxWindow int64 = 30 * 24 * 60 * 60
+	marketTradeHistoryLimit           = 50000
+)
+
+// MarketTradeHistory returns the market trades of the currency pair between start and end Unix timestamps,
+// newest first. Without start only the latest trades are returned, end defaults to now.
+// Ranges exceeding the exchange limits are fetched in chunks.
+func (client *Client) MarketTradeHistory(currencyPair string, start, end int64) (trades []Trade, err error) {
+	return client.MarketTradeHistoryCtx(context.Background(), currencyPair, start, end)
+}
+
+func (client *Client) MarketTradeHistoryCtx(ctx context.Context, currencyPair string, start, end int64) (trades []Trade, err error) {
+	if start <= 0 {
+		return client.marketTradeHistory(ctx, currencyPair, start, end)
+	}
+
+	if end <= 0 {
+		end = time.Now().Unix()
+	}
+
+	unique := map[uint64]Trade{}
+	for windowStart := start; windowStart < end; windowStart += marketTradeHistoryMaxWindow {
+		windowEnd := windowStart + marketTradeHistoryMaxWindow
+		if windowEnd > end {
+			windowEnd = end
+		}
+
+		if err = client.marketTradeHistoryWindow(ctx, currencyPair, windowStart, windowEnd, unique); err != nil {
+			return nil, err
+		}
+	}
+
+	trades = make([]Trade, 0, len(unique))
+	for _, trade := range unique {
+		trades = append(trades, trade)
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].GlobalTradeId > trades[j].GlobalTradeId
+	})
+
+	return trades, nil
+}
+
+// marketTradeHistoryWindow fetches trades of the window into unique, halving the window while the limit is hit
+func (client *Client) marketTradeHistoryWindow(ctx context.Context, currencyPair string, start, end int64, unique map[uint64]Trade) error {
+	trades, err := client.marketTradeHistory(ctx, currencyPair, start, end)
+	if err != nil {
+		return err
+	}
+
+	if len(trades) >= marketTradeHistoryLimit && end-start > 1 {
+		middle := start + (end-start)/2
+		if err := client.marketTradeHistoryWindow(ctx, currencyPair, start, middle, unique); err != nil {
+			return err
+		}
+
+		return client.marketTradeHistoryWindow(ctx, currencyPair, middle, end, unique)
+	}
+
+	for _, trade := range trades {
+		unique[trade.GlobalTradeId] = trade
+	}
+
+	return nil
+}
+
+func (client *Client) marketTradeHistory(ctx context.Context, currencyPair string, start, end int64) (trades []Trade, err error) {
+	params := Params{
+		"currencyPair": currencyPair,
+	}
+
+	if start > 0 {
+		params["start"] = fmt.Sprintf("%d", start)
+	}
+	if end > 0 {
+		params["end"] = fmt.Sprintf("%d", end)
+	}
+
+	err = client.publicApiRequest(ctx, &trades, "returnTradeHistory", params)
+
+	for i := range trades {
+		trades[i].CurrencyPair = currencyPair
+	}
+
+	return
+}
+
+type CandlePeriod int
+
+const (
+	CandlePeriod5Minutes  CandlePeriod = 300
+	CandlePeriod15Minutes CandlePeriod = 900
+	CandlePeriod30Minutes CandlePeriod = 1800
+	CandlePeriod2Hours    CandlePeriod = 7200
+	CandlePeriod4Hours    CandlePeriod = 14400
+	CandlePeriod1Day      CandlePeriod = 86400
+)
+
+func (period CandlePeriod) IsValid() bool {
+	switch period {
+	case CandlePeriod5Minutes, CandlePeriod15Minutes, CandlePeriod30Minutes,
+		CandlePeriod2Hours, CandlePeriod4Hours, CandlePeriod1Day:
+		return true
+	}
+
+	return false
+}
+
+func (period CandlePeriod) Duration() time.Duration {
+	return time.Duration(period) * time.Second
+}
+
+type Candle struct {
+	Date            time.Time       `json:"date"`
+	Open            decimal.Decimal `json:"open"`
+	High            decimal.Decimal `json:"high"`
+	Low             decimal.Decimal `json:"low"`
+	Close           decimal.Decimal `json:"close"`
+	Volume          decimal.Decimal `json:"volume"`
+	QuoteVolume     decimal.Decimal `json:"quoteVolume"`
+	WeightedAverage decimal.Decimal `json:"weightedAverage"`
+}
+
+func (candle *Candle) UnmarshalJSON(data []byte) error {
+	type plainCandle Candle
+	withDate := struct {
+		*plainCandle
+		Date convertibleTime `json:"date"`
+	}{plainCandle: (*plainCandle)(candle)}
+
+	if err := json.Unmarshal(data, &withDate); err != nil {
+		return err
+	}
+
+	candle.Date = time.Time(withDate.Date)
+	return nil
+}
+
+// ChartData returns candles of the currency pair between start and end Unix timestamps
+func (client *Client) ChartData(currencyPair string, period CandlePeriod, start, end int64) (candles []Candle, err error) {
+	return client.ChartDataCtx(context.Background(), currencyPair, period, start, end)
+}
+
+func (client *Client) ChartDataCtx(ctx context.Context, currencyPair string, period CandlePeriod, start, end int64) (candles []Candle, err error) {
+	if !period.IsValid() {
+		return nil, fmt.Errorf("unsupported candle period %d", period)
+	}
+
+	err = client.publicApiRequest(ctx, &candles, "returnChartData", Params{
+		"currencyPair": currencyPair,
+		"period":       fmt.Sprintf("%d", period),
+		"start":        fmt.Sprintf("%d", start),
+		"end":          fmt.Sprintf("%d", end),
+	})
+
+	// The exchange responds with a single zero candle when there is no data in the range
+	if len(candles) == 1 && candles[0].Date.Unix() == 0 {
+		candles = candles[:0]
+	}
+
+	return candles, err
+}
+
+func (client *Client) publicApiRequest(ctx context.Context, result interface{}, method string, params ...Params) error {
 	if len(params) > 1 {
 		return errors.New("too much arguments")
 	}
@@ -118,28 +413,42 @@ func (client *Client) publicApiRequest(result interface{}, method string, params
 		}
 	}
 
-	err := client.limiter.Wait(context.TODO())
-	if err != nil {
-		return err
+	for attempt := 1; ; attempt++ {
+		err := client.doPublicApiRequest(ctx, result, method, queryParams)
+		if err == nil || !client.retryPolicy.shouldRetry(method, false, attempt, err) {
+			return err
+		}
+
+		if err := client.retryPolicy.wait(ctx, attempt); err != nil {
+			return err
+		}
 	}
+}
 
-	response, err := client.resty.R().
-		SetQueryParams(queryParams).
-		Get(publicApiEndpoint)
+func (client *Client) doPublicApiRequest(ctx context.Context, result interface{}, method string, queryParams Params) error {
+	err := client.limiter.Wait(ctx)
 	if err != nil {
 		return err
 	}
 
-	errorResponse := errorResponse{}
-	err = json.Unmarshal(response.Body(), &errorResponse)
+	request := &ApiRequest{Command: method, Params: copyParams(queryParams)}
+	response, err := client.roundTrip(ctx, request, func(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
+		query := url.Values{}
+		for name, value := range request.Params {
+			query.Set(name, value)
+		}
+
+		httpRequest, err := http.NewRequest(http.MethodGet, client.publicEndpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return client.send(ctx, request.Command, httpRequest)
+	})
 	if err != nil {
 		return err
 	}
 
-	if errorResponse.Error != nil {
-		return errors.New(*errorResponse.Error)
-	}
-
-	err = json.Unmarshal(response.Body(), result)
+	err = json.Unmarshal(response.Body, result)
 	return err
 }
@@ -1,18 +1,20 @@
 package poloniex
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	. "github.com/smartystreets/goconvey/convey"
 	"golang.org/x/time/rate"
-	resty "gopkg.in/resty.v0"
 )
 
 type fakeHandler struct {
@@ -154,9 +156,186 @@ func TestClient_Ticker(t *testing.T) {
 	})
 }
 
+func TestClient_MarketTradeHistory(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var windows []string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				windows = append(windows, query.Get("start")+"-"+query.Get("end"))
+				id, _ := strconv.Atoi(query.Get("end"))
+				fmt.Fprintf(w, `[{"globalTradeID":%d,"tradeID":1,"date":"2014-02-10 04:23:23","type":"buy","rate":"0.00007600","amount":"140","total":"0.01064"},
+{"globalTradeID":2,"tradeID":2,"date":"2014-02-10 01:19:37","type":"sell","rate":"0.00007600","amount":"10","total":"0.00076"}]`, id+3)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		Convey("Should return latest trades without window", func() {
+			trades, err := client.MarketTradeHistory("BTC_NXT", 0, 0)
+			So(err, ShouldBeNil)
+			So(len(trades), ShouldEqual, 2)
+			So(trades[0].CurrencyPair, ShouldEqual, "BTC_NXT")
+			So(windows, ShouldResemble, []string{"-"})
+		})
+
+		Convey("Should split long range into windows and de-duplicate trades", func() {
+			defer func(window int64) { marketTradeHistoryMaxWindow = window }(marketTradeHistoryMaxWindow)
+			marketTradeHistoryMaxWindow = 100
+
+			trades, err := client.MarketTradeHistory("BTC_NXT", 1000, 1250)
+			So(err, ShouldBeNil)
+			So(windows, ShouldResemble, []string{"1000-1100", "1100-1200", "1200-1250"})
+			So(len(trades), ShouldEqual, 4)
+			So(trades[0].GlobalTradeId, ShouldEqual, 1253)
+			So(trades[3].GlobalTradeId, ShouldEqual, 2)
+		})
+
+		Convey("Should halve windows hitting the result limit", func() {
+			defer func(limit int) { marketTradeHistoryLimit = limit }(marketTradeHistoryLimit)
+			marketTradeHistoryLimit = 2
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				windows = append(windows, query.Get("start")+"-"+query.Get("end"))
+				if query.Get("start") == "1000" && query.Get("end") == "1004" {
+					fmt.Fprint(w, `[{"globalTradeID":3},{"globalTradeID":2}]`)
+					return
+				}
+				fmt.Fprintf(w, `[{"globalTradeID":%s}]`, query.Get("end"))
+			}
+
+			trades, err := client.MarketTradeHistory("BTC_NXT", 1000, 1004)
+			So(err, ShouldBeNil)
+			So(windows, ShouldResemble, []string{"1000-1004", "1000-1002", "1002-1004"})
+			So(len(trades), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestClient_ChartData(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		requests := 0
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				fmt.Fprint(w, `[{"date":1405699200,"high":0.0045388,"low":0.00403001,"open":0.00404545,"close":0.00427592,"volume":44.11655644,"quoteVolume":10259.29079097,"weightedAverage":0.00430015}]`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return candles", func() {
+			candles, err := client.ChartData("BTC_XMR", CandlePeriod4Hours, 1405699200, 9999999999)
+			So(err, ShouldBeNil)
+			So(len(candles), ShouldEqual, 1)
+			So(candles[0].Date, ShouldEqual, time.Date(2014, 7, 18, 16, 0, 0, 0, time.UTC))
+			So(candles[0].Close.String(), ShouldEqual, "0.00427592")
+			So(candles[0].WeightedAverage.String(), ShouldEqual, "0.00430015")
+		})
+
+		Convey("Should return no candles for empty range", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `[{"date":0,"high":0,"low":0,"open":0,"close":0,"volume":0,"quoteVolume":0,"weightedAverage":0}]`)
+			}
+
+			candles, err := client.ChartData("BTC_XMR", CandlePeriod1Day, 1, 2)
+			So(err, ShouldBeNil)
+			So(len(candles), ShouldEqual, 0)
+		})
+
+		Convey("Should reject unsupported period before sending request", func() {
+			_, err := client.ChartData("BTC_XMR", CandlePeriod(60), 1, 2)
+			So(err, ShouldBeError)
+			So(requests, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestClient_Volume24h(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Query().Get("command") {
+				case "return24hVolume":
+					fmt.Fprint(w, `{"BTC_LTC":{"BTC":"2.23248854","LTC":"87.10381314"},"BTC_NXT":{"BTC":"0.981616","NXT":"14145"},
+"USDT_BTC":{"USDT":"3000.5","BTC":"0.5"},"totalBTC":"81.89657704","totalLTC":"78.52083806","totalUSDT":"3000.5"}`)
+				case "returnTicker":
+					fmt.Fprint(w, `{"BTC_LTC":{"last":"0.0251"},"BTC_NXT":{"last":"0.00005730"},"USDT_BTC":{"last":"6000"},"BTC_DEAD":{"last":"0.1"}}`)
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		Convey("Should return 24h volume", func() {
+			volume, err := client.Volume24h()
+			So(err, ShouldBeNil)
+
+			So(len(volume.Pairs), ShouldEqual, 3)
+			So(volume.Pairs["BTC_NXT"].Base.String(), ShouldEqual, "0.981616")
+			So(volume.Pairs["BTC_NXT"].Quote.String(), ShouldEqual, "14145")
+			So(volume.Pairs["USDT_BTC"].Base.String(), ShouldEqual, "3000.5")
+			So(len(volume.Totals), ShouldEqual, 3)
+			So(volume.Totals["BTC"].String(), ShouldEqual, "81.89657704")
+		})
+
+		Convey("Should rank markets by base volume", func() {
+			markets, err := client.MarketsByVolume("BTC")
+			So(err, ShouldBeNil)
+
+			So(len(markets), ShouldEqual, 2)
+			So(markets[0].CurrencyPair, ShouldEqual, "BTC_LTC")
+			So(markets[0].Last.String(), ShouldEqual, "0.0251")
+			So(markets[1].CurrencyPair, ShouldEqual, "BTC_NXT")
+		})
+
+		Convey("Should rank all markets without base currency", func() {
+			markets, err := client.MarketsByVolume("")
+			So(err, ShouldBeNil)
+
+			So(len(markets), ShouldEqual, 3)
+			So(markets[0].CurrencyPair, ShouldEqual, "USDT_BTC")
+		})
+	})
+}
+
+func TestClient_TickerCtx(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should return error on cancelled context", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := client.TickerCtx(ctx)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
 func TestClient_publicApiRequest(t *testing.T) {
 	type fields struct {
-		resty      *resty.Client
+		httpClient *http.Client
 		limiter    *rate.Limiter
 		handleFunc http.HandlerFunc
 	}
@@ -174,7 +353,7 @@ func TestClient_publicApiRequest(t *testing.T) {
 		{
 			name: "too much arguments",
 			fields: fields{
-				resty:      resty.DefaultClient,
+				httpClient: &http.Client{},
 				limiter:    nil,
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {},
 			},
@@ -191,7 +370,7 @@ func TestClient_publicApiRequest(t *testing.T) {
 		{
 			name: "limiter error",
 			fields: fields{
-				resty:      resty.DefaultClient,
+				httpClient: &http.Client{},
 				limiter:    rate.NewLimiter(1, 0),
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {},
 			},
@@ -207,8 +386,8 @@ func TestClient_publicApiRequest(t *testing.T) {
 		{
 			name: "unmarshal error",
 			fields: fields{
-				resty:   resty.DefaultClient,
-				limiter: rate.NewLimiter(1, 1),
+				httpClient: &http.Client{},
+				limiter:    rate.NewLimiter(1, 1),
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {
 					fmt.Fprint(w, `///`)
 				},
@@ -223,8 +402,8 @@ func TestClient_publicApiRequest(t *testing.T) {
 		{
 			name: "response error",
 			fields: fields{
-				resty:   resty.DefaultClient,
-				limiter: rate.NewLimiter(1, 1),
+				httpClient: &http.Client{},
+				limiter:    rate.NewLimiter(1, 1),
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {
 					fmt.Fprint(w, `{"error": "some"}`)
 				},
@@ -245,12 +424,13 @@ func TestClient_publicApiRequest(t *testing.T) {
 			server := createFakeServer(handler)
 			defer server.Close()
 			client := &Client{
-				resty:   tt.fields.resty,
-				limiter: tt.fields.limiter,
+				httpClient:     tt.fields.httpClient,
+				publicEndpoint: server.URL + publicApiPath,
+				limiter:        tt.fields.limiter,
 			}
 			transport := transportForTesting(server)
 			client.SetTransport(transport)
-			if err := client.publicApiRequest(tt.args.result, tt.args.method, tt.args.params...); (err != nil) != tt.wantErr {
+			if err := client.publicApiRequest(context.Background(), tt.args.result, tt.args.method, tt.args.params...); (err != nil) != tt.wantErr {
 				t.Errorf("Client.publicApiRequest() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,114 @@
+package poloniex
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryAttempts       = 3
+	defaultRetryInitialBackoff = 250 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+)
+
+// RetryPolicy describes how failed requests are retried.
+// Public commands and trading commands starting with "return" are considered idempotent
+// and are retried on transient failures. Trading commands rejected because of an invalid nonce
+// are always retried with a fresh nonce, as the exchange did not execute them.
+// Commands overrides this: true allows retrying transient failures of a command
+// (e.g. "buy" or "withdraw"), false disables any retry of it.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Commands       map[string]bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    defaultRetryAttempts,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+	}
+}
+
+func (policy RetryPolicy) shouldRetry(command string, trading bool, attempt int, err error) bool {
+	if attempt >= policy.MaxAttempts {
+		return false
+	}
+
+	allowed, overridden := policy.Commands[command]
+	if overridden && !allowed {
+		return false
+	}
+
+	if trading && errors.Is(err, ErrInvalidNonce) {
+		return true
+	}
+
+	if !isTransientError(err) {
+		return false
+	}
+
+	if overridden {
+		return allowed
+	}
+
+	return !trading || strings.HasPrefix(command, "return")
+}
+
+// backoff returns exponential delay for the attempt with jitter in the [delay/2, delay) range
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.InitialBackoff
+	for i := 1; i < attempt && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+		delay = policy.MaxBackoff
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)))
+}
+
+func (policy RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(policy.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		return apiError.Kind == ErrorKindRateLimited ||
+			apiError.Kind == ErrorKindMaintenance ||
+			apiError.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netError net.Error
+	return errors.As(err, &netError)
+}
+
+func (client *Client) SetRetryPolicy(policy RetryPolicy) {
+	client.retryPolicy = policy
+}
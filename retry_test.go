@@ -0,0 +1,116 @@
+package poloniex
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRetryPolicy_shouldRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		Commands:    map[string]bool{"withdraw": true, "returnBalances": false},
+	}
+	nonceError := newApiError("buy", http.StatusOK, "Nonce must be greater than 2. You provided 1.")
+	gatewayError := newApiError("any", http.StatusBadGateway, "Bad Gateway")
+
+	type args struct {
+		command string
+		trading bool
+		attempt int
+		err     error
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{"public transient", args{"returnTicker", false, 1, gatewayError}, true},
+		{"attempts exhausted", args{"returnTicker", false, 3, gatewayError}, false},
+		{"not transient", args{"returnTicker", false, 1, errors.New("some")}, false},
+		{"trading read transient", args{"returnOpenOrders", true, 1, gatewayError}, true},
+		{"trading write transient", args{"buy", true, 1, gatewayError}, false},
+		{"trading write invalid nonce", args{"buy", true, 1, nonceError}, true},
+		{"explicitly allowed write", args{"withdraw", true, 1, gatewayError}, true},
+		{"explicitly disabled read", args{"returnBalances", true, 1, nonceError}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.shouldRetry(tt.args.command, tt.args.trading, tt.args.attempt, tt.args.err); got != tt.want {
+				t.Errorf("RetryPolicy.shouldRetry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	Convey("Given retry policy", t, func() {
+		policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+		Convey("Backoff should grow and stay within bounds", func() {
+			So(policy.backoff(1), ShouldBeBetweenOrEqual, 50*time.Millisecond, 100*time.Millisecond)
+			So(policy.backoff(2), ShouldBeBetweenOrEqual, 100*time.Millisecond, 200*time.Millisecond)
+			So(policy.backoff(5), ShouldBeBetweenOrEqual, 150*time.Millisecond, 300*time.Millisecond)
+		})
+	})
+}
+
+func TestClient_retry(t *testing.T) {
+	Convey("Setup flaky server", t, func() {
+		requests := 0
+		handler := &fakeHandler{}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+		client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+
+		Convey("Should retry nonce-rejected trading request with fresh nonce", func() {
+			nonces := map[string]bool{}
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				r.ParseForm()
+				nonces[r.PostForm.Get("nonce")] = true
+				if requests == 1 {
+					fmt.Fprint(w, `{"error":"Nonce must be greater than 2. You provided 1."}`)
+					return
+				}
+				fmt.Fprint(w, `{"orderNumber":31226040,"resultingTrades":[]}`)
+			}
+
+			_, err := client.Buy("ANY", decimal.New(1, 0), decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			So(requests, ShouldEqual, 2)
+			So(len(nonces), ShouldEqual, 2)
+		})
+
+		Convey("Should not retry non-idempotent trading request", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusBadGateway)
+			}
+
+			_, err := client.Buy("ANY", decimal.New(1, 0), decimal.New(1, 0))
+			So(err, ShouldNotBeNil)
+			So(requests, ShouldEqual, 1)
+		})
+
+		Convey("Should retry public request until attempts are exhausted", func() {
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusBadGateway)
+			}
+
+			_, err := client.Ticker()
+			So(err, ShouldNotBeNil)
+			So(requests, ShouldEqual, 3)
+		})
+	})
+}
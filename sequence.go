@@ -0,0 +1,143 @@
+package poloniex
+
+import (
+	"sync"
+	"time"
+)
+
+// SequenceGap is sent to the pair message channel when events of the pair are missing or out of order.
+// An order book built from the stream is corrupt afterwards and must be fetched again.
+type SequenceGap struct {
+	Pair     string
+	Expected uint
+	Got      uint
+}
+
+type sequencedEvent struct {
+	sequence uint
+	args     []interface{}
+	gap      *SequenceGap
+}
+
+// sequenceTracker orders events of a pair by sequence. Up to window events ahead of the expected one are held back
+// waiting for the missing ones, a gap is reported when the window overflows or the events are held for timeout.
+type sequenceTracker struct {
+	mutex    sync.Mutex
+	pair     string
+	window   int
+	timeout  time.Duration
+	timer    *time.Timer
+	started  bool
+	expected uint
+	pending  map[uint][]interface{}
+}
+
+func newSequenceTracker(pair string, window int, timeout time.Duration) *sequenceTracker {
+	return &sequenceTracker{
+		pair:    pair,
+		window:  window,
+		timeout: timeout,
+		pending: make(map[uint][]interface{}),
+	}
+}
+
+// push returns the events ready for delivery in order, with gaps reported before the events following them
+func (tracker *sequenceTracker) push(sequence uint, args []interface{}) (events []sequencedEvent) {
+	if !tracker.started {
+		tracker.started = true
+		tracker.expected = sequence
+	}
+
+	// A late event is delivered after its gap, the events following it are already delivered
+	if sequence < tracker.expected {
+		return []sequencedEvent{
+			{gap: &SequenceGap{Pair: tracker.pair, Expected: tracker.expected, Got: sequence}},
+			{sequence: sequence, args: args},
+		}
+	}
+
+	tracker.pending[sequence] = args
+
+	events = tracker.release()
+	for len(tracker.pending) > tracker.window {
+		events = append(events, tracker.skip()...)
+	}
+
+	return events
+}
+
+// flush releases every held back event, with gaps reported before them
+func (tracker *sequenceTracker) flush() (events []sequencedEvent) {
+	for len(tracker.pending) > 0 {
+		events = append(events, tracker.skip()...)
+	}
+
+	return events
+}
+
+// schedule arms the flush deadline while events are held back and disarms it otherwise.
+// Events flushed on the deadline are passed to deliver with the mutex locked, like those returned by push.
+func (tracker *sequenceTracker) schedule(deliver func(events []sequencedEvent)) {
+	if len(tracker.pending) == 0 || tracker.timeout <= 0 {
+		if tracker.timer != nil {
+			tracker.timer.Stop()
+			tracker.timer = nil
+		}
+		return
+	}
+
+	if tracker.timer != nil {
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(tracker.timeout, func() {
+		tracker.mutex.Lock()
+		defer tracker.mutex.Unlock()
+
+		// The deadline may have been disarmed, or armed again, while waiting for the mutex
+		if tracker.timer != timer {
+			return
+		}
+		tracker.timer = nil
+
+		deliver(tracker.flush())
+	})
+	tracker.timer = timer
+}
+
+// release returns the held back events following the expected one without interruption
+func (tracker *sequenceTracker) release() (events []sequencedEvent) {
+	for {
+		args, ok := tracker.pending[tracker.expected]
+		if !ok {
+			return events
+		}
+
+		delete(tracker.pending, tracker.expected)
+		events = append(events, sequencedEvent{sequence: tracker.expected, args: args})
+		tracker.expected++
+	}
+}
+
+// skip reports the missing events before the lowest held back one and releases the events following the gap
+func (tracker *sequenceTracker) skip() []sequencedEvent {
+	lowest := tracker.lowestPending()
+	events := []sequencedEvent{{gap: &SequenceGap{Pair: tracker.pair, Expected: tracker.expected, Got: lowest}}}
+	tracker.expected = lowest
+
+	return append(events, tracker.release()...)
+}
+
+func (tracker *sequenceTracker) lowestPending() uint {
+	first := true
+	var lowest uint
+	for sequence := range tracker.pending {
+		if first || sequence < lowest {
+			lowest = sequence
+			first = false
+		}
+	}
+
+	return lowest
+}
@@ -0,0 +1,110 @@
+package poloniex
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_sequenceTracker_push(t *testing.T) {
+	type pushed struct {
+		sequence uint
+		gap      *SequenceGap
+	}
+	tests := []struct {
+		name      string
+		window    int
+		sequences []uint
+		want      []pushed
+	}{
+		{
+			name:      "in order",
+			sequences: []uint{5, 6, 7},
+			want:      []pushed{{sequence: 5}, {sequence: 6}, {sequence: 7}},
+		},
+		{
+			name:      "gap without window",
+			sequences: []uint{5, 7, 8},
+			want: []pushed{
+				{sequence: 5},
+				{gap: &SequenceGap{Pair: "BTC_ETH", Expected: 6, Got: 7}},
+				{sequence: 7},
+				{sequence: 8},
+			},
+		},
+		{
+			name:      "late event without window",
+			sequences: []uint{5, 7, 6, 8},
+			want: []pushed{
+				{sequence: 5},
+				{gap: &SequenceGap{Pair: "BTC_ETH", Expected: 6, Got: 7}},
+				{sequence: 7},
+				{gap: &SequenceGap{Pair: "BTC_ETH", Expected: 8, Got: 6}},
+				{sequence: 6},
+				{sequence: 8},
+			},
+		},
+		{
+			name:      "reordered within window",
+			window:    2,
+			sequences: []uint{5, 7, 8, 6, 9},
+			want:      []pushed{{sequence: 5}, {sequence: 6}, {sequence: 7}, {sequence: 8}, {sequence: 9}},
+		},
+		{
+			name:      "gap over window",
+			window:    2,
+			sequences: []uint{5, 7, 8, 9, 10},
+			want: []pushed{
+				{sequence: 5},
+				{gap: &SequenceGap{Pair: "BTC_ETH", Expected: 6, Got: 7}},
+				{sequence: 7},
+				{sequence: 8},
+				{sequence: 9},
+				{sequence: 10},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newSequenceTracker("BTC_ETH", tt.window, 0)
+
+			var got []pushed
+			for _, sequence := range tt.sequences {
+				for _, event := range tracker.push(sequence, nil) {
+					got = append(got, pushed{sequence: event.sequence, gap: event.gap})
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sequenceTracker.push() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sequenceTracker_flush(t *testing.T) {
+	tracker := newSequenceTracker("BTC_ETH", 5, 0)
+	tracker.push(1, nil)
+	tracker.push(3, nil)
+	tracker.push(4, nil)
+	tracker.push(7, nil)
+
+	var got []uint
+	var gaps []SequenceGap
+	for _, event := range tracker.flush() {
+		if event.gap != nil {
+			gaps = append(gaps, *event.gap)
+			continue
+		}
+		got = append(got, event.sequence)
+	}
+
+	if want := []uint{3, 4, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sequenceTracker.flush() sequences = %v, want %v", got, want)
+	}
+	if want := []SequenceGap{{"BTC_ETH", 2, 3}, {"BTC_ETH", 5, 7}}; !reflect.DeepEqual(gaps, want) {
+		t.Errorf("sequenceTracker.flush() gaps = %+v, want %+v", gaps, want)
+	}
+	if events := tracker.push(8, nil); len(events) != 1 || events[0].sequence != 8 {
+		t.Errorf("sequenceTracker.push() after flush = %+v, want sequence 8", events)
+	}
+}
@@ -0,0 +1,287 @@
+package poloniex
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+const defaultSubscriptionBufferSize = 256
+
+var ErrSubscriptionOverflow = errors.New("subscription buffer overflow")
+
+// OverflowPolicy decides what happens to an event when the consumer lags behind and the channel buffer is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for the consumer, stalling the websocket reader meanwhile
+	OverflowBlock OverflowPolicy = iota
+	OverflowDropOldest
+	OverflowDropNewest
+	// OverflowDisconnect closes the subscription, Err reports ErrSubscriptionOverflow afterwards
+	OverflowDisconnect
+)
+
+type SubscriptionOptions struct {
+	// BufferSize is the capacity of every channel, defaults to 256
+	BufferSize int
+	Overflow   OverflowPolicy
+}
+
+// Subscription delivers events of a pair to typed channels. Events of one channel arrive in sequence order,
+// events of different channels are ordered by their Sequence. The channels are closed after Close.
+// A consumer must drain every channel, unless events of the channel may be dropped.
+type Subscription struct {
+	// dropped is accessed atomically and kept first for 64-bit alignment
+	dropped uint64
+
+	Pair               string
+	OrderModifications <-chan OrderModification
+	Trades             <-chan NewTrade
+	Gaps               <-chan SequenceGap
+	Errors             <-chan error
+
+	unsubscribe        func() error
+	overflow           OverflowPolicy
+	orderModifications chan OrderModification
+	trades             chan NewTrade
+	gaps               chan SequenceGap
+	errors             chan error
+
+	mutex     sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	closeOnce sync.Once
+	errMutex  sync.Mutex
+	closeErr  error
+}
+
+// Subscribe subscribes to events of the pair, like SubscribeToPair, with typed channels
+func (wampClient *WampClient) Subscribe(pair string, options SubscriptionOptions) (*Subscription, error) {
+	subscription := newSubscription(pair, options, func() error {
+		return wampClient.UnsubscribeFromPair(pair)
+	})
+
+	handler := marketMessageHandler(subscription, pair, newSequenceTracker(pair, wampClient.reorderWindow, wampClient.reorderTimeout))
+	if err := wampClient.subscribe(pair, handler); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func newSubscription(pair string, options SubscriptionOptions, unsubscribe func() error) *Subscription {
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultSubscriptionBufferSize
+	}
+
+	subscription := &Subscription{
+		Pair:               pair,
+		unsubscribe:        unsubscribe,
+		overflow:           options.Overflow,
+		orderModifications: make(chan OrderModification, bufferSize),
+		trades:             make(chan NewTrade, bufferSize),
+		gaps:               make(chan SequenceGap, bufferSize),
+		errors:             make(chan error, bufferSize),
+		done:               make(chan struct{}),
+	}
+	subscription.OrderModifications = subscription.orderModifications
+	subscription.Trades = subscription.trades
+	subscription.Gaps = subscription.gaps
+	subscription.Errors = subscription.errors
+
+	return subscription
+}
+
+// Dropped returns the number of events dropped because of overflow
+func (subscription *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&subscription.dropped)
+}
+
+// Err returns ErrSubscriptionOverflow after the subscription is closed because of overflow
+func (subscription *Subscription) Err() error {
+	subscription.errMutex.Lock()
+	defer subscription.errMutex.Unlock()
+
+	return subscription.closeErr
+}
+
+// Close unsubscribes from the pair and closes the channels
+func (subscription *Subscription) Close() error {
+	subscription.closeOnce.Do(func() {
+		close(subscription.done)
+	})
+
+	// Waits for a delivery in progress, which is interrupted by done
+	subscription.mutex.Lock()
+	if subscription.closed {
+		subscription.mutex.Unlock()
+		return nil
+	}
+	subscription.closed = true
+	close(subscription.orderModifications)
+	close(subscription.trades)
+	close(subscription.gaps)
+	close(subscription.errors)
+	subscription.mutex.Unlock()
+
+	return subscription.unsubscribe()
+}
+
+func (subscription *Subscription) orderModification(orderModification OrderModification) {
+	channel := subscription.orderModifications
+	subscription.deliver(
+		func() bool {
+			select {
+			case channel <- orderModification:
+				return true
+			default:
+				return false
+			}
+		},
+		func() {
+			select {
+			case channel <- orderModification:
+			case <-subscription.done:
+			}
+		},
+		func() bool {
+			select {
+			case <-channel:
+				return true
+			default:
+				return false
+			}
+		})
+}
+
+func (subscription *Subscription) newTrade(newTrade NewTrade) {
+	channel := subscription.trades
+	subscription.deliver(
+		func() bool {
+			select {
+			case channel <- newTrade:
+				return true
+			default:
+				return false
+			}
+		},
+		func() {
+			select {
+			case channel <- newTrade:
+			case <-subscription.done:
+			}
+		},
+		func() bool {
+			select {
+			case <-channel:
+				return true
+			default:
+				return false
+			}
+		})
+}
+
+func (subscription *Subscription) sequenceGap(gap SequenceGap) {
+	channel := subscription.gaps
+	subscription.deliver(
+		func() bool {
+			select {
+			case channel <- gap:
+				return true
+			default:
+				return false
+			}
+		},
+		func() {
+			select {
+			case channel <- gap:
+			case <-subscription.done:
+			}
+		},
+		func() bool {
+			select {
+			case <-channel:
+				return true
+			default:
+				return false
+			}
+		})
+}
+
+func (subscription *Subscription) err(err error) {
+	channel := subscription.errors
+	subscription.deliver(
+		func() bool {
+			select {
+			case channel <- err:
+				return true
+			default:
+				return false
+			}
+		},
+		func() {
+			select {
+			case channel <- err:
+			case <-subscription.done:
+			}
+		},
+		func() bool {
+			select {
+			case <-channel:
+				return true
+			default:
+				return false
+			}
+		})
+}
+
+// deliver applies the overflow policy. trySend sends without blocking, send blocks until sent or closed,
+// evict removes the oldest buffered event.
+func (subscription *Subscription) deliver(trySend func() bool, send func(), evict func() bool) {
+	subscription.mutex.RLock()
+	defer subscription.mutex.RUnlock()
+
+	select {
+	case <-subscription.done:
+		return
+	default:
+	}
+
+	switch subscription.overflow {
+	case OverflowBlock:
+		send()
+
+	case OverflowDropOldest:
+		for !trySend() {
+			if evict() {
+				atomic.AddUint64(&subscription.dropped, 1)
+			}
+		}
+
+	case OverflowDropNewest:
+		if !trySend() {
+			atomic.AddUint64(&subscription.dropped, 1)
+		}
+
+	case OverflowDisconnect:
+		if !trySend() {
+			atomic.AddUint64(&subscription.dropped, 1)
+			subscription.disconnect()
+		}
+	}
+}
+
+// disconnect stops deliveries at once, the channels are closed after the current delivery is over
+func (subscription *Subscription) disconnect() {
+	subscription.errMutex.Lock()
+	subscription.closeErr = ErrSubscriptionOverflow
+	subscription.errMutex.Unlock()
+
+	subscription.closeOnce.Do(func() {
+		close(subscription.done)
+	})
+
+	go subscription.Close()
+}
@@ -0,0 +1,106 @@
+package poloniex
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSubscription(t *testing.T) {
+	Convey("Given subscription with buffer of 2", t, func() {
+		unsubscribed := make(chan bool, 1)
+		newTestSubscription := func(overflow OverflowPolicy) *Subscription {
+			return newSubscription("BTC_ETH", SubscriptionOptions{BufferSize: 2, Overflow: overflow}, func() error {
+				unsubscribed <- true
+				return nil
+			})
+		}
+		trade := func(sequence uint) NewTrade {
+			return NewTrade{Sequence: sequence}
+		}
+		received := func(subscription *Subscription) (sequences []uint) {
+			for len(subscription.Trades) > 0 {
+				sequences = append(sequences, (<-subscription.Trades).Sequence)
+			}
+			return sequences
+		}
+
+		Convey("It should drop oldest events", func() {
+			subscription := newTestSubscription(OverflowDropOldest)
+			for sequence := uint(1); sequence <= 4; sequence++ {
+				subscription.newTrade(trade(sequence))
+			}
+
+			So(received(subscription), ShouldResemble, []uint{3, 4})
+			So(subscription.Dropped(), ShouldEqual, 2)
+		})
+
+		Convey("It should drop newest events", func() {
+			subscription := newTestSubscription(OverflowDropNewest)
+			for sequence := uint(1); sequence <= 4; sequence++ {
+				subscription.newTrade(trade(sequence))
+			}
+
+			So(received(subscription), ShouldResemble, []uint{1, 2})
+			So(subscription.Dropped(), ShouldEqual, 2)
+		})
+
+		Convey("It should disconnect on overflow", func() {
+			subscription := newTestSubscription(OverflowDisconnect)
+			for sequence := uint(1); sequence <= 4; sequence++ {
+				subscription.newTrade(trade(sequence))
+			}
+
+			So(<-unsubscribed, ShouldBeTrue)
+			So(subscription.Err(), ShouldEqual, ErrSubscriptionOverflow)
+			So(subscription.Dropped(), ShouldEqual, 1)
+
+			var sequences []uint
+			for newTrade := range subscription.Trades {
+				sequences = append(sequences, newTrade.Sequence)
+			}
+			So(sequences, ShouldResemble, []uint{1, 2})
+		})
+
+		Convey("It should block until the consumer reads", func() {
+			subscription := newTestSubscription(OverflowBlock)
+			delivered := make(chan bool)
+			go func() {
+				for sequence := uint(1); sequence <= 3; sequence++ {
+					subscription.newTrade(trade(sequence))
+				}
+				delivered <- true
+			}()
+
+			So((<-subscription.Trades).Sequence, ShouldEqual, 1)
+			So(<-delivered, ShouldBeTrue)
+			So(received(subscription), ShouldResemble, []uint{2, 3})
+			So(subscription.Dropped(), ShouldEqual, 0)
+		})
+
+		Convey("It should stop blocked delivery and close channels on Close", func() {
+			subscription := newTestSubscription(OverflowBlock)
+			delivered := make(chan bool)
+			go func() {
+				for sequence := uint(1); sequence <= 3; sequence++ {
+					subscription.newTrade(trade(sequence))
+				}
+				subscription.err(errors.New("late"))
+				delivered <- true
+			}()
+
+			for len(subscription.Trades) < 2 {
+				time.Sleep(time.Millisecond)
+			}
+			So(subscription.Close(), ShouldBeNil)
+			So(<-delivered, ShouldBeTrue)
+			So(<-unsubscribed, ShouldBeTrue)
+			So(subscription.Err(), ShouldBeNil)
+
+			_, open := <-subscription.Errors
+			So(open, ShouldBeFalse)
+		})
+	})
+}
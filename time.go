@@ -0,0 +1,52 @@
+package poloniex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02 15:04:05"
+
+// convertibleTime parses exchange dates given either as "2006-01-02 15:04:05" in UTC or as Unix seconds,
+// RFC 3339 is accepted as well so marshalled values can be read back
+type convertibleTime time.Time
+
+func (ct *convertibleTime) UnmarshalJSON(data []byte) error {
+	asString := strings.Trim(string(data), `"`)
+	if asString == "" || asString == "null" {
+		*ct = convertibleTime(time.Time{})
+		return nil
+	}
+
+	if seconds, err := strconv.ParseInt(asString, 10, 64); err == nil {
+		*ct = convertibleTime(time.Unix(seconds, 0).UTC())
+		return nil
+	}
+
+	parsed, err := parseDate(asString)
+	if err != nil {
+		// Dates marshalled by time.Time itself
+		parsed, err = time.Parse(time.RFC3339Nano, asString)
+	}
+	if err != nil {
+		return fmt.Errorf("time unmarshal error: invalid input %s", asString)
+	}
+
+	*ct = convertibleTime(parsed)
+	return nil
+}
+
+func parseDate(date string) (time.Time, error) {
+	return time.ParseInLocation(dateLayout, date, time.UTC)
+}
+
+// unixOrZero converts time to Unix seconds keeping zero time as 0, which means "not set" in requests
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
@@ -0,0 +1,38 @@
+package poloniex
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_convertibleTime_UnmarshalJSON(t *testing.T) {
+	type args struct {
+		data []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantErr bool
+		want    time.Time
+	}{
+		{"from date string", args{[]byte(`"2016-04-05 08:08:40"`)}, false, time.Date(2016, 4, 5, 8, 8, 40, 0, time.UTC)},
+		{"from epoch number", args{[]byte(`1506005439`)}, false, time.Date(2017, 9, 21, 14, 50, 39, 0, time.UTC)},
+		{"from epoch string", args{[]byte(`"1506005439"`)}, false, time.Date(2017, 9, 21, 14, 50, 39, 0, time.UTC)},
+		{"from RFC 3339", args{[]byte(`"2017-09-21T14:50:39Z"`)}, false, time.Date(2017, 9, 21, 14, 50, 39, 0, time.UTC)},
+		{"from null", args{[]byte(`null`)}, false, time.Time{}},
+		{"with error", args{[]byte(`"30.08.2017"`)}, true, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctime := new(convertibleTime)
+			err := ctime.UnmarshalJSON(tt.args.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("convertibleTime.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !time.Time(*ctime).Equal(tt.want) {
+				t.Errorf("convertibleTime.UnmarshalJSON() value = %v, want %v", time.Time(*ctime), tt.want)
+			}
+		})
+	}
+}
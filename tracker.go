@@ -0,0 +1,194 @@
+package poloniex
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultTrackerInitialInterval = 5 * time.Second
+	defaultTrackerMaxInterval     = time.Minute
+	defaultTrackerLookback        = 10 * time.Minute
+)
+
+// WithdrawalUpdate carries the withdrawal after a status change.
+// Updates with Err report failed polls; the tracking goes on unless the channel is closed afterwards.
+type WithdrawalUpdate struct {
+	Withdrawal Withdrawal
+	Err        error
+}
+
+// WithdrawalTracker submits withdrawals and follows them in returnDepositsWithdrawals until they reach a final status
+type WithdrawalTracker struct {
+	client *Client
+
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// Lookback widens the polled period before submission to tolerate clock skew with the exchange
+	Lookback time.Duration
+
+	mutex sync.Mutex
+	// claimed withdrawal numbers are followed already, they are kept until no withdrawal is in flight
+	claimed  map[uint]bool
+	inFlight int
+}
+
+func NewWithdrawalTracker(client *Client) *WithdrawalTracker {
+	return &WithdrawalTracker{
+		client:          client,
+		InitialInterval: defaultTrackerInitialInterval,
+		MaxInterval:     defaultTrackerMaxInterval,
+		Lookback:        defaultTrackerLookback,
+		claimed:         make(map[uint]bool),
+	}
+}
+
+// Withdraw submits the withdrawal and returns a channel receiving its status transitions.
+// The withdrawal record is identified as the first new withdrawal of the same currency, address and amount
+// not followed by another withdrawal of the tracker.
+// The channel is closed after a final status, or when ctx is done, in which case the last update carries ctx.Err().
+func (tracker *WithdrawalTracker) Withdraw(ctx context.Context, currency, address string, amount decimal.Decimal) (<-chan WithdrawalUpdate, error) {
+	since := time.Now().Add(-tracker.Lookback)
+
+	// Claims must outlive every withdrawal whose known numbers may miss them
+	tracker.start()
+
+	known, err := tracker.client.DepositsWithdrawalsBetweenCtx(ctx, since, time.Time{})
+	if err != nil {
+		tracker.finish()
+		return nil, err
+	}
+
+	knownNumbers := map[uint]bool{}
+	for _, withdrawal := range known.Withdrawals {
+		knownNumbers[withdrawal.WithdrawalNumber] = true
+	}
+
+	if _, err := tracker.client.WithdrawCtx(ctx, currency, address, amount); err != nil {
+		tracker.finish()
+		return nil, err
+	}
+
+	updates := make(chan WithdrawalUpdate, 1)
+	go tracker.follow(ctx, updates, since, knownNumbers, func(withdrawal *Withdrawal) bool {
+		return withdrawal.Currency == currency &&
+			withdrawal.Address == address &&
+			withdrawal.Amount.Equal(amount)
+	})
+
+	return updates, nil
+}
+
+func (tracker *WithdrawalTracker) follow(ctx context.Context, updates chan WithdrawalUpdate, since time.Time, knownNumbers map[uint]bool, matches func(*Withdrawal) bool) {
+	defer close(updates)
+	defer tracker.finish()
+
+	var current *Withdrawal
+	interval := tracker.InitialInterval
+
+	for {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			update := WithdrawalUpdate{Err: ctx.Err()}
+			if current != nil {
+				update.Withdrawal = *current
+			}
+			// The consumer may have stopped reading after cancelling, an unread update is replaced
+			// so the last update still carries the error
+			for {
+				select {
+				case updates <- update:
+					return
+				default:
+				}
+
+				select {
+				case <-updates:
+				default:
+				}
+			}
+		case <-timer.C:
+		}
+
+		if interval *= 2; interval > tracker.MaxInterval {
+			interval = tracker.MaxInterval
+		}
+
+		response, err := tracker.client.DepositsWithdrawalsBetweenCtx(ctx, since, time.Time{})
+		if err != nil {
+			if ctx.Err() == nil && !tracker.send(ctx, updates, WithdrawalUpdate{Err: err}) {
+				return
+			}
+			continue
+		}
+
+		found := tracker.find(response.Withdrawals, current, knownNumbers, matches)
+		if found == nil || (current != nil && found.RawStatus == current.RawStatus) {
+			continue
+		}
+
+		current = found
+		if !tracker.send(ctx, updates, WithdrawalUpdate{Withdrawal: *current}) || current.Status.IsFinal() {
+			return
+		}
+	}
+}
+
+func (tracker *WithdrawalTracker) find(withdrawals []*Withdrawal, current *Withdrawal, knownNumbers map[uint]bool, matches func(*Withdrawal) bool) *Withdrawal {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	var found *Withdrawal
+	for _, withdrawal := range withdrawals {
+		if current != nil {
+			if withdrawal.WithdrawalNumber == current.WithdrawalNumber {
+				return withdrawal
+			}
+			continue
+		}
+
+		if knownNumbers[withdrawal.WithdrawalNumber] || tracker.claimed[withdrawal.WithdrawalNumber] || !matches(withdrawal) {
+			continue
+		}
+
+		if found == nil || withdrawal.WithdrawalNumber < found.WithdrawalNumber {
+			found = withdrawal
+		}
+	}
+
+	if current == nil && found != nil {
+		tracker.claimed[found.WithdrawalNumber] = true
+	}
+
+	return found
+}
+
+func (tracker *WithdrawalTracker) start() {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	tracker.inFlight++
+}
+
+func (tracker *WithdrawalTracker) finish() {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	if tracker.inFlight--; tracker.inFlight == 0 {
+		tracker.claimed = make(map[uint]bool)
+	}
+}
+
+func (tracker *WithdrawalTracker) send(ctx context.Context, updates chan<- WithdrawalUpdate, update WithdrawalUpdate) bool {
+	select {
+	case updates <- update:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,169 @@
+package poloniex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWithdrawalTracker_Withdraw(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		const oldWithdrawal = `{"withdrawalNumber":1,"currency":"BTC","address":"xyz","amount":"1.00000000","fee":"0.0001","timestamp":1506010932,"status":"COMPLETE: aaa","ipAddress":"1.2.3.4"}`
+		statuses := []string{"", "PENDING", "PENDING", "AWAITING APPROVAL", "COMPLETE: bbb"}
+		polls := 0
+		withdrawn := false
+
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				switch r.PostForm.Get("command") {
+				case "withdraw":
+					withdrawn = true
+					fmt.Fprint(w, `{"response":"Withdrew 1.00000000 BTC."}`)
+				case "returnDepositsWithdrawals":
+					status := statuses[polls]
+					if polls < len(statuses)-1 {
+						polls++
+					}
+					if status == "" {
+						fmt.Fprintf(w, `{"deposits":[],"withdrawals":[%s]}`, oldWithdrawal)
+						return
+					}
+					fmt.Fprintf(w, `{"deposits":[],"withdrawals":[%s,
+{"withdrawalNumber":3,"currency":"BTC","address":"other","amount":"1.00000000","fee":"0.0001","timestamp":1506010932,"status":"PENDING","ipAddress":"1.2.3.4"},
+{"withdrawalNumber":2,"currency":"BTC","address":"xyz","amount":"1.00000000","fee":"0.0001","timestamp":1506010933,"status":"%s","ipAddress":"1.2.3.4"}]}`, oldWithdrawal, status)
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		tracker := NewWithdrawalTracker(client)
+		tracker.InitialInterval = time.Millisecond
+		tracker.MaxInterval = 5 * time.Millisecond
+
+		Convey("Should follow withdrawal to final status", func() {
+			updates, err := tracker.Withdraw(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			So(withdrawn, ShouldBeTrue)
+
+			var received []TransferStatus
+			var last WithdrawalUpdate
+			for update := range updates {
+				So(update.Err, ShouldBeNil)
+				received = append(received, update.Withdrawal.Status)
+				last = update
+			}
+
+			So(received, ShouldResemble, []TransferStatus{TransferStatusPending, TransferStatusAwaitingApproval, TransferStatusComplete})
+			So(last.Withdrawal.WithdrawalNumber, ShouldEqual, 2)
+			So(last.Withdrawal.Txid, ShouldEqual, "bbb")
+		})
+
+		Convey("Should stop with context error on timeout", func() {
+			statuses = []string{"", "PENDING"}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			updates, err := tracker.Withdraw(ctx, "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+
+			var last WithdrawalUpdate
+			for update := range updates {
+				last = update
+			}
+
+			So(last.Err, ShouldEqual, context.DeadlineExceeded)
+			So(last.Withdrawal.Status, ShouldEqual, TransferStatusPending)
+		})
+
+		Convey("Should replace unread update with context error", func() {
+			statuses = []string{"", "PENDING"}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			updates, err := tracker.Withdraw(ctx, "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+
+			var received []WithdrawalUpdate
+			for update := range updates {
+				received = append(received, update)
+			}
+
+			So(received, ShouldHaveLength, 1)
+			So(received[0].Err, ShouldEqual, context.DeadlineExceeded)
+			So(received[0].Withdrawal.Status, ShouldEqual, TransferStatusPending)
+		})
+	})
+}
+
+func TestWithdrawalTracker_Withdraw_identical(t *testing.T) {
+	Convey("Setup server listing withdrawals of the same amount", t, func() {
+		var mutex sync.Mutex
+		withdrawn := 0
+
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				r.ParseForm()
+				switch r.PostForm.Get("command") {
+				case "withdraw":
+					withdrawn++
+					fmt.Fprint(w, `{"response":"Withdrew 1.00000000 BTC."}`)
+				case "returnDepositsWithdrawals":
+					if withdrawn < 2 {
+						fmt.Fprint(w, `{"deposits":[],"withdrawals":[]}`)
+						return
+					}
+					fmt.Fprint(w, `{"deposits":[],"withdrawals":[
+{"withdrawalNumber":2,"currency":"BTC","address":"xyz","amount":"1.00000000","fee":"0.0001","timestamp":1506010932,"status":"COMPLETE: aaa","ipAddress":"1.2.3.4"},
+{"withdrawalNumber":3,"currency":"BTC","address":"xyz","amount":"1.00000000","fee":"0.0001","timestamp":1506010933,"status":"COMPLETE: bbb","ipAddress":"1.2.3.4"}]}`)
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		tracker := NewWithdrawalTracker(client)
+		tracker.InitialInterval = time.Millisecond
+		tracker.MaxInterval = 5 * time.Millisecond
+
+		Convey("Should follow a separate record for every withdrawal", func() {
+			first, err := tracker.Withdraw(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+			second, err := tracker.Withdraw(context.Background(), "BTC", "xyz", decimal.New(1, 0))
+			So(err, ShouldBeNil)
+
+			var numbers []uint
+			for _, updates := range []<-chan WithdrawalUpdate{first, second} {
+				for update := range updates {
+					So(update.Err, ShouldBeNil)
+					numbers = append(numbers, update.Withdrawal.WithdrawalNumber)
+				}
+			}
+
+			sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+			So(numbers, ShouldResemble, []uint{2, 3})
+		})
+	})
+}
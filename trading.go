@@ -8,13 +8,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
 )
 
-const tradingApiEndpoint = "https://poloniex.com/tradingApi"
-
 const (
 	TypeBuy  = "buy"
 	TypeSell = "sell"
@@ -28,21 +29,37 @@ type FeeInfo struct {
 }
 
 func (client *Client) FeeInfo() (feeInfo FeeInfo, err error) {
-	err = client.tradingApiRequest(&feeInfo, "returnFeeInfo")
+	return client.FeeInfoCtx(context.Background())
+}
+
+func (client *Client) FeeInfoCtx(ctx context.Context) (feeInfo FeeInfo, err error) {
+	err = client.tradingApiRequest(ctx, &feeInfo, "returnFeeInfo")
 	return
 }
 
 func (client *Client) Balances() (balances map[string]decimal.Decimal, err error) {
-	err = client.tradingApiRequest(&balances, "returnBalances")
+	return client.BalancesCtx(context.Background())
+}
+
+func (client *Client) BalancesCtx(ctx context.Context) (balances map[string]decimal.Decimal, err error) {
+	err = client.tradingApiRequest(ctx, &balances, "returnBalances")
 	return
 }
 
 func (client *Client) DepositAddresses() (addresses map[string]string, err error) {
-	err = client.tradingApiRequest(&addresses, "returnDepositAddresses")
+	return client.DepositAddressesCtx(context.Background())
+}
+
+func (client *Client) DepositAddressesCtx(ctx context.Context) (addresses map[string]string, err error) {
+	err = client.tradingApiRequest(ctx, &addresses, "returnDepositAddresses")
 	return
 }
 
 func (client *Client) NewAddress(currency string) (address string, err error) {
+	return client.NewAddressCtx(context.Background(), currency)
+}
+
+func (client *Client) NewAddressCtx(ctx context.Context, currency string) (address string, err error) {
 	params := Params{
 		"currency": currency,
 	}
@@ -52,7 +69,7 @@ func (client *Client) NewAddress(currency string) (address string, err error) {
 		Response string          `json:"response"`
 	}{}
 
-	if err = client.tradingApiRequest(&result, "generateNewAddress", params); err != nil {
+	if err = client.tradingApiRequest(ctx, &result, "generateNewAddress", params); err != nil {
 		return result.Response, err
 	}
 
@@ -73,10 +90,29 @@ type Trade struct {
 	Amount        decimal.Decimal
 	Total         decimal.Decimal
 	Fee           decimal.Decimal
-	Date          string
+	Date          time.Time
+}
+
+func (trade *Trade) UnmarshalJSON(data []byte) error {
+	type plainTrade Trade
+	withDate := struct {
+		*plainTrade
+		Date convertibleTime `json:"date"`
+	}{plainTrade: (*plainTrade)(trade)}
+
+	if err := json.Unmarshal(data, &withDate); err != nil {
+		return err
+	}
+
+	trade.Date = time.Time(withDate.Date)
+	return nil
 }
 
 func (client *Client) TradeHistory(currencyPair string, start, end int64) (trades []Trade, err error) {
+	return client.TradeHistoryCtx(context.Background(), currencyPair, start, end)
+}
+
+func (client *Client) TradeHistoryCtx(ctx context.Context, currencyPair string, start, end int64) (trades []Trade, err error) {
 	params := Params{
 		"currencyPair": currencyPair,
 	}
@@ -88,7 +124,7 @@ func (client *Client) TradeHistory(currencyPair string, start, end int64) (trade
 		params["end"] = fmt.Sprintf("%d", end)
 	}
 
-	err = client.tradingApiRequest(&trades, "returnTradeHistory", params)
+	err = client.tradingApiRequest(ctx, &trades, "returnTradeHistory", params)
 
 	for i := range trades {
 		trades[i].CurrencyPair = currencyPair
@@ -97,7 +133,19 @@ func (client *Client) TradeHistory(currencyPair string, start, end int64) (trade
 	return
 }
 
+func (client *Client) TradeHistoryBetween(currencyPair string, start, end time.Time) (trades []Trade, err error) {
+	return client.TradeHistoryBetweenCtx(context.Background(), currencyPair, start, end)
+}
+
+func (client *Client) TradeHistoryBetweenCtx(ctx context.Context, currencyPair string, start, end time.Time) (trades []Trade, err error) {
+	return client.TradeHistoryCtx(ctx, currencyPair, unixOrZero(start), unixOrZero(end))
+}
+
 func (client *Client) TradeHistoryAll(start, end int64) (trades map[string][]Trade, err error) {
+	return client.TradeHistoryAllCtx(context.Background(), start, end)
+}
+
+func (client *Client) TradeHistoryAllCtx(ctx context.Context, start, end int64) (trades map[string][]Trade, err error) {
 	params := Params{
 		"currencyPair": "all",
 	}
@@ -109,7 +157,7 @@ func (client *Client) TradeHistoryAll(start, end int64) (trades map[string][]Tra
 		params["end"] = fmt.Sprintf("%d", end)
 	}
 
-	err = client.tradingApiRequest(&trades, "returnTradeHistory", params)
+	err = client.tradingApiRequest(ctx, &trades, "returnTradeHistory", params)
 
 	for pair := range trades {
 		for i := range trades[pair] {
@@ -120,8 +168,20 @@ func (client *Client) TradeHistoryAll(start, end int64) (trades map[string][]Tra
 	return
 }
 
+func (client *Client) TradeHistoryAllBetween(start, end time.Time) (trades map[string][]Trade, err error) {
+	return client.TradeHistoryAllBetweenCtx(context.Background(), start, end)
+}
+
+func (client *Client) TradeHistoryAllBetweenCtx(ctx context.Context, start, end time.Time) (trades map[string][]Trade, err error) {
+	return client.TradeHistoryAllCtx(ctx, unixOrZero(start), unixOrZero(end))
+}
+
 func (client *Client) OrderTrades(orderNumber uint64) (trades []Trade, err error) {
-	err = client.tradingApiRequest(&trades, "returnOrderTrades",
+	return client.OrderTradesCtx(context.Background(), orderNumber)
+}
+
+func (client *Client) OrderTradesCtx(ctx context.Context, orderNumber uint64) (trades []Trade, err error) {
+	err = client.tradingApiRequest(ctx, &trades, "returnOrderTrades",
 		Params{"orderNumber": fmt.Sprintf("%d", orderNumber)})
 
 	for i := range trades {
@@ -140,14 +200,22 @@ type OwnOrder struct {
 }
 
 func (client *Client) OpenOrders(currencyPair string) (orders []OwnOrder, err error) {
-	err = client.tradingApiRequest(&orders, "returnOpenOrders", Params{
+	return client.OpenOrdersCtx(context.Background(), currencyPair)
+}
+
+func (client *Client) OpenOrdersCtx(ctx context.Context, currencyPair string) (orders []OwnOrder, err error) {
+	err = client.tradingApiRequest(ctx, &orders, "returnOpenOrders", Params{
 		"currencyPair": currencyPair,
 	})
 	return
 }
 
 func (client *Client) OpenOrdersAll() (orders map[string][]OwnOrder, err error) {
-	err = client.tradingApiRequest(&orders, "returnOpenOrders", Params{
+	return client.OpenOrdersAllCtx(context.Background())
+}
+
+func (client *Client) OpenOrdersAllCtx(ctx context.Context) (orders map[string][]OwnOrder, err error) {
+	err = client.tradingApiRequest(ctx, &orders, "returnOpenOrders", Params{
 		"currencyPair": "all",
 	})
 	return
@@ -163,57 +231,158 @@ type UpdatedOrder struct {
 	ResultingTrades map[string][]Trade
 }
 
+type TimeInForce int
+
+const (
+	GoodTillCancelled TimeInForce = iota
+	FillOrKill
+	ImmediateOrCancel
+	// PostOnly orders are only placed when they do not fill immediately, so they never pay taker fees
+	PostOnly
+)
+
+var timeInForceParams = map[TimeInForce]string{
+	FillOrKill:        "fillOrKill",
+	ImmediateOrCancel: "immediateOrCancel",
+	PostOnly:          "postOnly",
+}
+
+type OrderRequest struct {
+	// Type is either TypeBuy or TypeSell
+	Type         string
+	CurrencyPair string
+	Rate         decimal.Decimal
+	Amount       decimal.Decimal
+	TimeInForce  TimeInForce
+}
+
+func (request OrderRequest) Validate() error {
+	if request.Type != TypeBuy && request.Type != TypeSell {
+		return fmt.Errorf("invalid order type %q", request.Type)
+	}
+
+	if request.CurrencyPair == "" {
+		return errors.New("currency pair is required")
+	}
+
+	if !request.Rate.GreaterThan(decimal.Zero) {
+		return fmt.Errorf("order rate must be positive, got %s", request.Rate)
+	}
+
+	if !request.Amount.GreaterThan(decimal.Zero) {
+		return fmt.Errorf("order amount must be positive, got %s", request.Amount)
+	}
+
+	if _, ok := timeInForceParams[request.TimeInForce]; !ok && request.TimeInForce != GoodTillCancelled {
+		return fmt.Errorf("invalid time in force %d", request.TimeInForce)
+	}
+
+	return nil
+}
+
+func (request OrderRequest) params() Params {
+	params := Params{
+		"currencyPair": request.CurrencyPair,
+		"rate":         request.Rate.String(),
+		"amount":       request.Amount.String(),
+	}
+
+	if param, ok := timeInForceParams[request.TimeInForce]; ok {
+		params[param] = "1"
+	}
+
+	return params
+}
+
+// PlaceOrder validates the request and places a buy or sell order
+func (client *Client) PlaceOrder(request OrderRequest) (placedOrder PlacedOrder, err error) {
+	return client.PlaceOrderCtx(context.Background(), request)
+}
+
+func (client *Client) PlaceOrderCtx(ctx context.Context, request OrderRequest) (placedOrder PlacedOrder, err error) {
+	if err = request.Validate(); err != nil {
+		return placedOrder, err
+	}
+
+	err = client.tradingApiRequest(ctx, &placedOrder, request.Type, request.params())
+	return
+}
+
 func (client *Client) Buy(currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
-	err = client.tradingApiRequest(&placedOrder, "buy", Params{
-		"currencyPair": currencyPair,
-		"rate":         rate.String(),
-		"amount":       amount.String(),
+	return client.BuyCtx(context.Background(), currencyPair, rate, amount)
+}
+
+func (client *Client) BuyCtx(ctx context.Context, currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
+	return client.PlaceOrderCtx(ctx, OrderRequest{
+		Type:         TypeBuy,
+		CurrencyPair: currencyPair,
+		Rate:         rate,
+		Amount:       amount,
 	})
-	return
 }
 
 func (client *Client) Sell(currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
-	err = client.tradingApiRequest(&placedOrder, "sell", Params{
-		"currencyPair": currencyPair,
-		"rate":         rate.String(),
-		"amount":       amount.String(),
+	return client.SellCtx(context.Background(), currencyPair, rate, amount)
+}
+
+func (client *Client) SellCtx(ctx context.Context, currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
+	return client.PlaceOrderCtx(ctx, OrderRequest{
+		Type:         TypeSell,
+		CurrencyPair: currencyPair,
+		Rate:         rate,
+		Amount:       amount,
 	})
-	return
 }
 
 // BuyFOK creates buy method with "Fill or Kill" option enabled
 func (client *Client) BuyFOK(currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
-	err = client.tradingApiRequest(&placedOrder, "buy", Params{
-		"currencyPair": currencyPair,
-		"rate":         rate.String(),
-		"amount":       amount.String(),
-		"fillOrKill":   "1",
+	return client.BuyFOKCtx(context.Background(), currencyPair, rate, amount)
+}
+
+func (client *Client) BuyFOKCtx(ctx context.Context, currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
+	return client.PlaceOrderCtx(ctx, OrderRequest{
+		Type:         TypeBuy,
+		CurrencyPair: currencyPair,
+		Rate:         rate,
+		Amount:       amount,
+		TimeInForce:  FillOrKill,
 	})
-	return
 }
 
 // SellFOK creates sell method with "Fill or Kill" option enabled
 func (client *Client) SellFOK(currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
-	err = client.tradingApiRequest(&placedOrder, "sell", Params{
-		"currencyPair": currencyPair,
-		"rate":         rate.String(),
-		"amount":       amount.String(),
-		"fillOrKill":   "1",
+	return client.SellFOKCtx(context.Background(), currencyPair, rate, amount)
+}
+
+func (client *Client) SellFOKCtx(ctx context.Context, currencyPair string, rate, amount decimal.Decimal) (placedOrder PlacedOrder, err error) {
+	return client.PlaceOrderCtx(ctx, OrderRequest{
+		Type:         TypeSell,
+		CurrencyPair: currencyPair,
+		Rate:         rate,
+		Amount:       amount,
+		TimeInForce:  FillOrKill,
 	})
-	return
 }
 
 func (client *Client) CancelOrder(orderNumber uint64) (success bool, err error) {
+	return client.CancelOrderCtx(context.Background(), orderNumber)
+}
+
+func (client *Client) CancelOrderCtx(ctx context.Context, orderNumber uint64) (success bool, err error) {
 	result := struct {
 		Success convertibleBool
 	}{}
-	err = client.tradingApiRequest(&result, "cancelOrder",
+	err = client.tradingApiRequest(ctx, &result, "cancelOrder",
 		Params{"orderNumber": fmt.Sprintf("%d", orderNumber)})
 	success = bool(result.Success)
 	return
 }
 
 func (client *Client) MoveOrder(orderNumber uint64, rate, amount decimal.Decimal) (updatedOrder UpdatedOrder, err error) {
+	return client.MoveOrderCtx(context.Background(), orderNumber, rate, amount)
+}
+
+func (client *Client) MoveOrderCtx(ctx context.Context, orderNumber uint64, rate, amount decimal.Decimal) (updatedOrder UpdatedOrder, err error) {
 	result := struct {
 		Success convertibleBool
 		UpdatedOrder
@@ -228,7 +397,7 @@ func (client *Client) MoveOrder(orderNumber uint64, rate, amount decimal.Decimal
 		params["amount"] = amount.String()
 	}
 
-	err = client.tradingApiRequest(&result, "moveOrder", params)
+	err = client.tradingApiRequest(ctx, &result, "moveOrder", params)
 
 	if !result.Success {
 		err = errors.New("result is not successful")
@@ -240,6 +409,10 @@ func (client *Client) MoveOrder(orderNumber uint64, rate, amount decimal.Decimal
 }
 
 func (client *Client) Withdraw(currency, address string, amount decimal.Decimal) (response string, err error) {
+	return client.WithdrawCtx(context.Background(), currency, address, amount)
+}
+
+func (client *Client) WithdrawCtx(ctx context.Context, currency, address string, amount decimal.Decimal) (response string, err error) {
 	result := struct {
 		Response string
 	}{}
@@ -250,35 +423,20 @@ func (client *Client) Withdraw(currency, address string, amount decimal.Decimal)
 		"amount":   amount.String(),
 	}
 
-	err = client.tradingApiRequest(&result, "withdraw", params)
+	err = client.tradingApiRequest(ctx, &result, "withdraw", params)
 	return result.Response, err
 }
 
 type DepositsWithdrawalsResponse struct {
-	Deposits []*struct {
-		DepositNumber uint   `json:"depositNumber"`
-		Currency      string `json:"currency"`
-		Address       string `json:"address"`
-		Amount        string `json:"amount"`
-		Confirmations uint   `json:"confirmations"`
-		Txid          string `json:"txid"`
-		Timestamp     uint   `json:"timestamp"`
-		Status        string `json:"status"`
-	} `json:"deposits"`
-	Withdrawals []*struct {
-		WithdrawalNumber uint    `json:"withdrawalNumber"`
-		Currency         string  `json:"currency"`
-		Address          string  `json:"address"`
-		Amount           string  `json:"amount"`
-		Fee              string  `json:"fee"`
-		Timestamp        uint    `json:"timestamp"`
-		Status           string  `json:"status"`
-		IPAddress        string  `json:"ipAddress"`
-		PaymentID        *string `json:"paymentID"`
-	} `json:"withdrawals"`
+	Deposits    []*Deposit    `json:"deposits"`
+	Withdrawals []*Withdrawal `json:"withdrawals"`
 }
 
 func (client *Client) DepositsWithdrawals(start, end int64) (response DepositsWithdrawalsResponse, err error) {
+	return client.DepositsWithdrawalsCtx(context.Background(), start, end)
+}
+
+func (client *Client) DepositsWithdrawalsCtx(ctx context.Context, start, end int64) (response DepositsWithdrawalsResponse, err error) {
 	params := Params{}
 	if start > 0 {
 		params["start"] = fmt.Sprintf("%d", start)
@@ -287,17 +445,21 @@ func (client *Client) DepositsWithdrawals(start, end int64) (response DepositsWi
 		params["end"] = fmt.Sprintf("%d", end)
 	}
 
-	err = client.tradingApiRequest(&response, "returnDepositsWithdrawals", params)
+	err = client.tradingApiRequest(ctx, &response, "returnDepositsWithdrawals", params)
 	return
 }
 
-type errorResponse struct {
-	Error *string
+func (client *Client) DepositsWithdrawalsBetween(start, end time.Time) (response DepositsWithdrawalsResponse, err error) {
+	return client.DepositsWithdrawalsBetweenCtx(context.Background(), start, end)
+}
+
+func (client *Client) DepositsWithdrawalsBetweenCtx(ctx context.Context, start, end time.Time) (response DepositsWithdrawalsResponse, err error) {
+	return client.DepositsWithdrawalsCtx(ctx, unixOrZero(start), unixOrZero(end))
 }
 
 type emptyArrayResponse []struct{}
 
-func (client *Client) tradingApiRequest(result interface{}, method string, params ...Params) (err error) {
+func (client *Client) tradingApiRequest(ctx context.Context, result interface{}, method string, params ...Params) (err error) {
 	if len(params) > 1 {
 		return errors.New("too much arguments")
 	}
@@ -312,46 +474,83 @@ func (client *Client) tradingApiRequest(result interface{}, method string, param
 		}
 	}
 
-	err = client.limiter.Wait(context.TODO())
+	for attempt := 1; ; attempt++ {
+		err = client.doTradingApiRequest(ctx, result, method, formData)
+		if err == nil || !client.retryPolicy.shouldRetry(method, true, attempt, err) {
+			return err
+		}
+
+		if err := client.retryPolicy.wait(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+func (client *Client) doTradingApiRequest(ctx context.Context, result interface{}, method string, formData Params) (err error) {
+	err = client.limiter.Wait(ctx)
 	if err != nil {
 		return err
 	}
 
-	key := client.keyPool.Get()
+	key, err := client.keyPool.GetCtx(ctx)
+	if err != nil {
+		return err
+	}
 
-	nonce := time.Now().UnixNano()
+	nonce, err := client.nonceSource.Nonce(key.Key)
+	if err != nil {
+		client.keyPool.Put(key)
+		return err
+	}
 	formData["nonce"] = fmt.Sprintf("%d", nonce)
 
-	request := client.resty.R().
-		SetFormData(formData)
+	request := &ApiRequest{Command: method, Trading: true, Params: copyParams(formData), Key: key.Key}
+	response, err := client.roundTrip(ctx, request, func(ctx context.Context, request *ApiRequest) (*ApiResponse, error) {
+		form := url.Values{}
+		for name, value := range request.Params {
+			form.Set(name, value)
+		}
+		encodedForm := form.Encode()
 
-	signature := hmac.New(sha512.New, []byte(key.Secret))
-	signature.Write([]byte(request.FormData.Encode()))
+		signature := hmac.New(sha512.New, []byte(key.Secret))
+		signature.Write([]byte(encodedForm))
 
-	request.SetHeader("Key", key.Key).
-		SetHeader("Sign", hex.EncodeToString(signature.Sum(nil)))
+		httpRequest, err := http.NewRequest(http.MethodPost, client.tradingEndpoint, strings.NewReader(encodedForm))
+		if err != nil {
+			return nil, err
+		}
+		httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		httpRequest.Header.Set("Key", key.Key)
+		httpRequest.Header.Set("Sign", hex.EncodeToString(signature.Sum(nil)))
 
-	response, err := request.Post(tradingApiEndpoint)
+		return client.send(ctx, request.Command, httpRequest)
+	})
 	client.keyPool.Put(key)
 	if err != nil {
+		client.resyncNonce(key, err)
 		return err
 	}
 
-	errorResponse := errorResponse{}
-	json.Unmarshal(response.Body(), &errorResponse)
-	if errorResponse.Error != nil {
-		return errors.New(*errorResponse.Error)
-	}
-
 	emptyArrayResponse := emptyArrayResponse{}
-	err = json.Unmarshal(response.Body(), &emptyArrayResponse)
+	err = json.Unmarshal(response.Body, &emptyArrayResponse)
 	if err == nil && len(emptyArrayResponse) == 0 {
 		return nil
 	}
 
-	err = json.Unmarshal(response.Body(), result)
+	err = json.Unmarshal(response.Body, result)
 	if err != nil {
-		err = fmt.Errorf("%s\nServer response: %s", err.Error(), string(response.Body()))
+		err = fmt.Errorf("%s\nServer response: %s", err.Error(), string(response.Body))
 	}
 	return err
 }
+
+func (client *Client) resyncNonce(key *Key, err error) {
+	var apiError *APIError
+	if !errors.As(err, &apiError) || apiError.Kind != ErrorKindInvalidNonce {
+		return
+	}
+
+	if expected, ok := expectedNonce(apiError.Message); ok {
+		client.nonceSource.Resync(key.Key, expected)
+	}
+}
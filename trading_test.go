@@ -1,14 +1,15 @@
 package poloniex
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	. "github.com/smartystreets/goconvey/convey"
 	"golang.org/x/time/rate"
-	resty "gopkg.in/resty.v0"
 )
 
 func TestClient_FeeInfo(t *testing.T) {
@@ -137,6 +138,21 @@ func TestClient_TradeHistory(t *testing.T) {
 			So(len(tradeHistory), ShouldEqual, 2)
 			So(tradeHistory[0].Rate.String(), ShouldEqual, "0.02565498")
 			So(tradeHistory[0].CurrencyPair, ShouldEqual, "ANY")
+			So(tradeHistory[0].Date, ShouldEqual, time.Date(2016, 4, 5, 8, 8, 40, 0, time.UTC))
+		})
+
+		Convey("Should return trade history between times", func() {
+			var start, end string
+			handler.HandleFunc = func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				start, end = r.PostForm.Get("start"), r.PostForm.Get("end")
+				fmt.Fprint(w, `[]`)
+			}
+
+			_, err := client.TradeHistoryBetween("ANY", time.Unix(1459843200, 0), time.Time{})
+			So(err, ShouldBeNil)
+			So(start, ShouldEqual, "1459843200")
+			So(end, ShouldEqual, "")
 		})
 	})
 }
@@ -248,7 +264,7 @@ func TestClient_Buy(t *testing.T) {
 		client.SetTransport(transportForTesting(server))
 
 		Convey("Should buy", func() {
-			placedOrder, err := client.Buy("ANY", decimal.Zero, decimal.Zero)
+			placedOrder, err := client.Buy("ANY", decimal.New(1, 0), decimal.New(1, 0))
 			So(err, ShouldBeNil)
 
 			So(placedOrder.ResultingTrades[0].Rate.String(), ShouldEqual, "0.00000173")
@@ -270,7 +286,7 @@ func TestClient_Sell(t *testing.T) {
 		client.SetTransport(transportForTesting(server))
 
 		Convey("Should sell", func() {
-			placedOrder, err := client.Sell("ANY", decimal.Zero, decimal.Zero)
+			placedOrder, err := client.Sell("ANY", decimal.New(1, 0), decimal.New(1, 0))
 			So(err, ShouldBeNil)
 
 			So(placedOrder.ResultingTrades[0].Rate.String(), ShouldEqual, "0.00000173")
@@ -292,7 +308,7 @@ func TestClient_BuyFOK(t *testing.T) {
 		client.SetTransport(transportForTesting(server))
 
 		Convey("Should buy", func() {
-			placedOrder, err := client.BuyFOK("ANY", decimal.Zero, decimal.Zero)
+			placedOrder, err := client.BuyFOK("ANY", decimal.New(1, 0), decimal.New(1, 0))
 			So(err, ShouldBeNil)
 
 			So(placedOrder.ResultingTrades[0].Rate.String(), ShouldEqual, "0.00000174")
@@ -314,7 +330,7 @@ func TestClient_SellFOK(t *testing.T) {
 		client.SetTransport(transportForTesting(server))
 
 		Convey("Should sell", func() {
-			placedOrder, err := client.SellFOK("ANY", decimal.Zero, decimal.Zero)
+			placedOrder, err := client.SellFOK("ANY", decimal.New(1, 0), decimal.New(1, 0))
 			So(err, ShouldBeNil)
 
 			So(placedOrder.ResultingTrades[0].Rate.String(), ShouldEqual, "0.00000174")
@@ -322,6 +338,76 @@ func TestClient_SellFOK(t *testing.T) {
 	})
 }
 
+func TestClient_PlaceOrder(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		var form map[string]string
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				form = map[string]string{}
+				for name := range r.PostForm {
+					form[name] = r.PostForm.Get(name)
+				}
+				fmt.Fprint(w, `{"orderNumber":31226040,"resultingTrades":[]}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		request := OrderRequest{
+			Type:         TypeBuy,
+			CurrencyPair: "BTC_ETH",
+			Rate:         decimal.New(1, -2),
+			Amount:       decimal.New(1, 0),
+			TimeInForce:  PostOnly,
+		}
+
+		Convey("Should place post-only order", func() {
+			placedOrder, err := client.PlaceOrder(request)
+			So(err, ShouldBeNil)
+
+			So(uint64(placedOrder.OrderNumber), ShouldEqual, 31226040)
+			So(form["command"], ShouldEqual, "buy")
+			So(form["postOnly"], ShouldEqual, "1")
+			_, ok := form["fillOrKill"]
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("Should place immediate-or-cancel order", func() {
+			request.Type = TypeSell
+			request.TimeInForce = ImmediateOrCancel
+			_, err := client.PlaceOrder(request)
+			So(err, ShouldBeNil)
+
+			So(form["command"], ShouldEqual, "sell")
+			So(form["immediateOrCancel"], ShouldEqual, "1")
+		})
+
+		Convey("Should reject invalid request before sending", func() {
+			form = nil
+
+			request.Amount = decimal.Zero
+			_, err := client.PlaceOrder(request)
+			So(err, ShouldBeError)
+
+			request.Amount = decimal.New(1, 0)
+			request.Type = "short"
+			_, err = client.PlaceOrder(request)
+			So(err, ShouldBeError)
+
+			request.Type = TypeBuy
+			request.TimeInForce = TimeInForce(42)
+			_, err = client.PlaceOrder(request)
+			So(err, ShouldBeError)
+
+			So(form, ShouldBeNil)
+		})
+	})
+}
+
 func TestClient_CancellOrder(t *testing.T) {
 	Convey("Setup correct server", t, func() {
 		handler := &fakeHandler{
@@ -409,12 +495,40 @@ func TestClient_DepositsWithdrawals(t *testing.T) {
 		So(err, ShouldBeNil)
 
 		So(response, ShouldHaveSameTypeAs, DepositsWithdrawalsResponse{})
+		So(response.Deposits[0].Timestamp, ShouldEqual, time.Date(2017, 9, 21, 14, 50, 39, 0, time.UTC))
+		So(response.Withdrawals[0].Timestamp, ShouldEqual, time.Date(2017, 9, 21, 16, 22, 12, 0, time.UTC))
+	})
+}
+
+func TestClient_FeeInfoCtx(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, `{"makerFee": "0.00140000", "takerFee": "0.00240000", "thirtyDayVolume": "612.00248891", "nextTier": "1200.00000000"}`)
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+
+		Convey("Should stop waiting for a key when context is done", func() {
+			key := client.keyPool.Get()
+			defer client.keyPool.Put(key)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			_, err := client.FeeInfoCtx(ctx)
+			So(err, ShouldEqual, context.DeadlineExceeded)
+		})
 	})
 }
 
 func TestClient_tradingApiRequest(t *testing.T) {
 	type fields struct {
-		resty      *resty.Client
+		httpClient *http.Client
 		limiter    *rate.Limiter
 		handleFunc http.HandlerFunc
 	}
@@ -432,7 +546,7 @@ func TestClient_tradingApiRequest(t *testing.T) {
 		{
 			name: "too much arguments",
 			fields: fields{
-				resty:      resty.DefaultClient,
+				httpClient: &http.Client{},
 				limiter:    nil,
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {},
 			},
@@ -449,7 +563,7 @@ func TestClient_tradingApiRequest(t *testing.T) {
 		{
 			name: "limiter error",
 			fields: fields{
-				resty:      resty.DefaultClient,
+				httpClient: &http.Client{},
 				limiter:    rate.NewLimiter(1, 0),
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {},
 			},
@@ -465,8 +579,8 @@ func TestClient_tradingApiRequest(t *testing.T) {
 		{
 			name: "unmarshal error",
 			fields: fields{
-				resty:   resty.DefaultClient,
-				limiter: rate.NewLimiter(1, 1),
+				httpClient: &http.Client{},
+				limiter:    rate.NewLimiter(1, 1),
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {
 					fmt.Fprint(w, `///`)
 				},
@@ -481,8 +595,8 @@ func TestClient_tradingApiRequest(t *testing.T) {
 		{
 			name: "empty array",
 			fields: fields{
-				resty:   resty.DefaultClient,
-				limiter: rate.NewLimiter(1, 1),
+				httpClient: &http.Client{},
+				limiter:    rate.NewLimiter(1, 1),
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {
 					fmt.Fprint(w, `[]`)
 				},
@@ -497,8 +611,8 @@ func TestClient_tradingApiRequest(t *testing.T) {
 		{
 			name: "response error",
 			fields: fields{
-				resty:   resty.DefaultClient,
-				limiter: rate.NewLimiter(1, 1),
+				httpClient: &http.Client{},
+				limiter:    rate.NewLimiter(1, 1),
 				handleFunc: func(w http.ResponseWriter, r *http.Request) {
 					fmt.Fprint(w, `{"error": "some"}`)
 				},
@@ -522,13 +636,15 @@ func TestClient_tradingApiRequest(t *testing.T) {
 				keyPool: keyPool{
 					keys: make(chan *Key, 1),
 				},
-				resty:   tt.fields.resty,
-				limiter: tt.fields.limiter,
+				httpClient:      tt.fields.httpClient,
+				tradingEndpoint: server.URL + tradingApiPath,
+				limiter:         tt.fields.limiter,
+				nonceSource:     NewMonotonicNonceSource(),
 			}
 			client.keyPool.Put(&Key{"KEY", "SECRET"})
 			transport := transportForTesting(server)
 			client.SetTransport(transport)
-			if err := client.tradingApiRequest(tt.args.result, tt.args.method, tt.args.params...); (err != nil) != tt.wantErr {
+			if err := client.tradingApiRequest(context.Background(), tt.args.result, tt.args.method, tt.args.params...); (err != nil) != tt.wantErr {
 				t.Errorf("Client.tradingApiRequest() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
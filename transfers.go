@@ -0,0 +1,149 @@
+package poloniex
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type TransferStatus int
+
+const (
+	TransferStatusUnknown TransferStatus = iota
+	TransferStatusPending
+	TransferStatusAwaitingApproval
+	TransferStatusComplete
+	TransferStatusCancelled
+	TransferStatusFailed
+)
+
+var transferStatuses = map[string]TransferStatus{
+	"PENDING":           TransferStatusPending,
+	"PROCESSING":        TransferStatusPending,
+	"AWAITING APPROVAL": TransferStatusAwaitingApproval,
+	"COMPLETE":          TransferStatusComplete,
+	"COMPLETED":         TransferStatusComplete,
+	"CANCELED":          TransferStatusCancelled,
+	"CANCELLED":         TransferStatusCancelled,
+	"FAILED":            TransferStatusFailed,
+}
+
+func (status TransferStatus) String() string {
+	switch status {
+	case TransferStatusPending:
+		return "pending"
+	case TransferStatusAwaitingApproval:
+		return "awaiting approval"
+	case TransferStatusComplete:
+		return "complete"
+	case TransferStatusCancelled:
+		return "cancelled"
+	case TransferStatusFailed:
+		return "failed"
+	}
+
+	return "unknown"
+}
+
+// IsFinal reports whether the status can not change anymore
+func (status TransferStatus) IsFinal() bool {
+	return status == TransferStatusComplete || status == TransferStatusCancelled || status == TransferStatusFailed
+}
+
+// parseTransferStatus parses statuses like "PENDING" or "COMPLETE: <txid>"
+func parseTransferStatus(rawStatus string) (status TransferStatus, txid string) {
+	name := rawStatus
+	if separator := strings.Index(rawStatus, ":"); separator >= 0 {
+		name = rawStatus[:separator]
+		txid = strings.TrimSpace(rawStatus[separator+1:])
+	}
+
+	status, ok := transferStatuses[strings.ToUpper(strings.TrimSpace(name))]
+	if !ok {
+		return TransferStatusUnknown, txid
+	}
+
+	if status != TransferStatusComplete {
+		txid = ""
+	}
+
+	return status, txid
+}
+
+type Deposit struct {
+	DepositNumber uint            `json:"depositNumber"`
+	Currency      string          `json:"currency"`
+	Address       string          `json:"address"`
+	Amount        decimal.Decimal `json:"amount"`
+	Confirmations uint            `json:"confirmations"`
+	Txid          string          `json:"txid"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Status        TransferStatus  `json:"-"`
+	RawStatus     string          `json:"status"`
+}
+
+func (deposit *Deposit) UnmarshalJSON(data []byte) error {
+	type plainDeposit Deposit
+	withTimestamp := struct {
+		*plainDeposit
+		Timestamp convertibleTime `json:"timestamp"`
+	}{plainDeposit: (*plainDeposit)(deposit)}
+
+	if err := json.Unmarshal(data, &withTimestamp); err != nil {
+		return err
+	}
+
+	deposit.Timestamp = time.Time(withTimestamp.Timestamp)
+
+	var txid string
+	deposit.Status, txid = parseTransferStatus(deposit.RawStatus)
+	if deposit.Txid == "" {
+		deposit.Txid = txid
+	}
+
+	return nil
+}
+
+type Withdrawal struct {
+	WithdrawalNumber uint            `json:"withdrawalNumber"`
+	Currency         string          `json:"currency"`
+	Address          string          `json:"address"`
+	Amount           decimal.Decimal `json:"amount"`
+	Fee              decimal.Decimal `json:"fee"`
+	Timestamp        time.Time       `json:"timestamp"`
+	Status           TransferStatus  `json:"-"`
+	RawStatus        string          `json:"status"`
+	Txid             string          `json:"txid"`
+	IPAddress        string          `json:"ipAddress"`
+	PaymentID        *string         `json:"paymentID"`
+}
+
+func (withdrawal *Withdrawal) UnmarshalJSON(data []byte) error {
+	type plainWithdrawal Withdrawal
+	withTimestamp := struct {
+		*plainWithdrawal
+		Timestamp convertibleTime `json:"timestamp"`
+	}{plainWithdrawal: (*plainWithdrawal)(withdrawal)}
+
+	if err := json.Unmarshal(data, &withTimestamp); err != nil {
+		return err
+	}
+
+	withdrawal.Timestamp = time.Time(withTimestamp.Timestamp)
+
+	var txid string
+	withdrawal.Status, txid = parseTransferStatus(withdrawal.RawStatus)
+	if withdrawal.Txid == "" {
+		withdrawal.Txid = txid
+	}
+
+	return nil
+}
+
+func (withdrawal Withdrawal) String() string {
+	return fmt.Sprintf("withdrawal %d of %s %s to %s (%s)",
+		withdrawal.WithdrawalNumber, withdrawal.Amount, withdrawal.Currency, withdrawal.Address, withdrawal.Status)
+}
@@ -0,0 +1,78 @@
+package poloniex
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_parseTransferStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		rawStatus  string
+		wantStatus TransferStatus
+		wantTxid   string
+	}{
+		{"complete with txid", "COMPLETE: 0x4233463", TransferStatusComplete, "0x4233463"},
+		{"complete", "COMPLETE", TransferStatusComplete, ""},
+		{"pending", "PENDING", TransferStatusPending, ""},
+		{"awaiting approval", "AWAITING APPROVAL", TransferStatusAwaitingApproval, ""},
+		{"cancelled", "CANCELED", TransferStatusCancelled, ""},
+		{"failed", "FAILED", TransferStatusFailed, ""},
+		{"complete with error", "COMPLETE: ERROR", TransferStatusComplete, "ERROR"},
+		{"unknown", "SOMETHING", TransferStatusUnknown, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, txid := parseTransferStatus(tt.rawStatus)
+			if status != tt.wantStatus || txid != tt.wantTxid {
+				t.Errorf("parseTransferStatus() = %v, %q, want %v, %q", status, txid, tt.wantStatus, tt.wantTxid)
+			}
+		})
+	}
+}
+
+func TestWithdrawal_UnmarshalJSON(t *testing.T) {
+	Convey("Given withdrawal JSON", t, func() {
+		data := []byte(`{"withdrawalNumber":7397527,"currency":"ETC","address":"0x26419a62055af459d2cd69bb7392f5100b75e304","amount":"13.19951600","fee":"0.01000000","timestamp":1506010932,"status":"COMPLETE: 0x423346392f82ac16e8c2604f2a604b7b2382d0e9d8030f673821f8de4b5f5a30","ipAddress":"1.2.3.4","paymentID":null}`)
+
+		withdrawal := Withdrawal{}
+		So(json.Unmarshal(data, &withdrawal), ShouldBeNil)
+
+		Convey("It should parse amounts, status and txid", func() {
+			So(withdrawal.Amount.String(), ShouldEqual, "13.199516")
+			So(withdrawal.Fee.String(), ShouldEqual, "0.01")
+			So(withdrawal.Timestamp, ShouldEqual, time.Date(2017, 9, 21, 16, 22, 12, 0, time.UTC))
+			So(withdrawal.Status, ShouldEqual, TransferStatusComplete)
+			So(withdrawal.Status.IsFinal(), ShouldBeTrue)
+			So(withdrawal.Txid, ShouldEqual, "0x423346392f82ac16e8c2604f2a604b7b2382d0e9d8030f673821f8de4b5f5a30")
+		})
+
+		Convey("It should survive JSON round trip", func() {
+			encoded, err := json.Marshal(withdrawal)
+			So(err, ShouldBeNil)
+
+			decoded := Withdrawal{}
+			So(json.Unmarshal(encoded, &decoded), ShouldBeNil)
+			So(decoded.Status, ShouldEqual, withdrawal.Status)
+			So(decoded.Timestamp, ShouldEqual, withdrawal.Timestamp)
+			So(decoded.Amount.Equal(withdrawal.Amount), ShouldBeTrue)
+		})
+	})
+}
+
+func TestDeposit_UnmarshalJSON(t *testing.T) {
+	Convey("Given deposit JSON", t, func() {
+		data := []byte(`{"depositNumber":7397520,"currency":"BTC","address":"131rdg5Rzn6BFufnnQaHhVa5ZtRU1J2EZR","amount":"0.06830697","confirmations":1,"txid":"3a4b9b2404f6e6fb556c3e1d46a9752f5e70a93ac1718605c992b80aacd8bd1d","timestamp":1506005439,"status":"PENDING"}`)
+
+		deposit := Deposit{}
+		So(json.Unmarshal(data, &deposit), ShouldBeNil)
+
+		So(deposit.Amount.String(), ShouldEqual, "0.06830697")
+		So(deposit.Status, ShouldEqual, TransferStatusPending)
+		So(deposit.Status.IsFinal(), ShouldBeFalse)
+		So(deposit.Txid, ShouldEqual, "3a4b9b2404f6e6fb556c3e1d46a9752f5e70a93ac1718605c992b80aacd8bd1d")
+	})
+}
@@ -1,9 +1,14 @@
 package poloniex
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/shopspring/decimal"
@@ -14,6 +19,8 @@ const (
 	wampEndpoint = "wss://api.poloniex.com"
 	wampRealm    = "realm1"
 
+	defaultReorderTimeout = time.Second
+
 	messageTypeOrderBookRemove = "orderBookRemove"
 	messageTypeOrderBookModify = "orderBookModify"
 	messageTypeNewTrade        = "newTrade"
@@ -33,79 +40,365 @@ type NewTrade struct {
 	Trade
 }
 
+type ConnectionState int
+
+const (
+	ConnectionStateDisconnected ConnectionState = iota
+	ConnectionStateConnected
+	// ConnectionStateResubscribed follows a reconnection once the pairs are subscribed again
+	ConnectionStateResubscribed
+)
+
+func (state ConnectionState) String() string {
+	switch state {
+	case ConnectionStateDisconnected:
+		return "disconnected"
+	case ConnectionStateConnected:
+		return "connected"
+	case ConnectionStateResubscribed:
+		return "resubscribed"
+	}
+
+	return fmt.Sprintf("ConnectionState(%d)", int(state))
+}
+
+// ConnectionEvent reports a change of the WAMP connection. Messages may be lost between
+// a disconnection and the following resubscription, so consumers should invalidate their state.
+type ConnectionEvent struct {
+	State ConnectionState
+	// Attempt is the number of the reconnection attempt
+	Attempt int
+	// Topics lists the resubscribed pairs and channels
+	Topics []string
+	// Err is the cause of a disconnection, a failed reconnection attempt or resubscription
+	Err error
+}
+
 type WampClient struct {
-	client *turnpike.Client
+	mutex         sync.Mutex
+	client        *turnpike.Client
+	endpoint      string
+	realm         string
+	tlsConfig     *tls.Config
+	dial          turnpike.DialFunc
+	subscriptions map[string]turnpike.EventHandler
+
+	reorderWindow   int
+	reorderTimeout  time.Duration
+	reconnectPolicy *RetryPolicy
+	events          chan<- ConnectionEvent
+	ctx             context.Context
+	cancel          context.CancelFunc
 }
 
-func NewWampClient(tlsConfig *tls.Config, dial turnpike.DialFunc) (*WampClient, error) {
-	client, err := turnpike.NewWebsocketClient(turnpike.JSON, wampEndpoint, nil, tlsConfig, dial)
-	if err != nil {
+// WampClientOption configures a WampClient in NewWampClient
+type WampClientOption func(wampClient *WampClient)
+
+// WithWampEndpoint connects to another WAMP router, like "ws://localhost:8000"
+func WithWampEndpoint(endpoint string) WampClientOption {
+	return func(wampClient *WampClient) {
+		wampClient.endpoint = endpoint
+	}
+}
+
+func WithWampRealm(realm string) WampClientOption {
+	return func(wampClient *WampClient) {
+		wampClient.realm = realm
+	}
+}
+
+// WithWampReorderWindow holds back up to window events of a pair arriving ahead of a missing one,
+// so slightly out of order events are delivered in order instead of being reported as gaps.
+// Held back events are released, after the gap, once the window overflows or the reorder timeout passes.
+func WithWampReorderWindow(window int) WampClientOption {
+	return func(wampClient *WampClient) {
+		wampClient.reorderWindow = window
+	}
+}
+
+// WithWampReorderTimeout limits how long events are held back by the reorder window, defaults to one second
+func WithWampReorderTimeout(timeout time.Duration) WampClientOption {
+	return func(wampClient *WampClient) {
+		wampClient.reorderTimeout = timeout
+	}
+}
+
+// WithWampReconnect makes the client reconnect after a connection loss, rejoin the realm
+// and subscribe again to every pair. Attempts are delayed by the policy backoff,
+// zero MaxAttempts means the client never gives up.
+func WithWampReconnect(policy RetryPolicy) WampClientOption {
+	return func(wampClient *WampClient) {
+		wampClient.reconnectPolicy = &policy
+	}
+}
+
+// WithWampConnectionEvents sends connection changes after the initial connection to events.
+// The client waits for events to be received, so the channel must be drained.
+func WithWampConnectionEvents(events chan<- ConnectionEvent) WampClientOption {
+	return func(wampClient *WampClient) {
+		wampClient.events = events
+	}
+}
+
+func NewWampClient(tlsConfig *tls.Config, dial turnpike.DialFunc, options ...WampClientOption) (*WampClient, error) {
+	wampClient := &WampClient{
+		endpoint:       wampEndpoint,
+		realm:          wampRealm,
+		tlsConfig:      tlsConfig,
+		dial:           dial,
+		subscriptions:  make(map[string]turnpike.EventHandler),
+		reorderTimeout: defaultReorderTimeout,
+	}
+	wampClient.ctx, wampClient.cancel = context.WithCancel(context.Background())
+
+	for _, option := range options {
+		option(wampClient)
+	}
+
+	if err := wampClient.connect(); err != nil {
+		wampClient.cancel()
 		return nil, err
 	}
 
-	_, err = client.JoinRealm(wampRealm, nil)
+	return wampClient, nil
+}
+
+func (wampClient *WampClient) connect() error {
+	client, err := turnpike.NewWebsocketClient(turnpike.JSON, wampClient.endpoint, nil, wampClient.tlsConfig, wampClient.dial)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if wampClient.reconnectPolicy != nil {
+		// turnpike signals the end of its receive loop here, the buffer keeps it from blocking
+		client.ReceiveDone = make(chan bool, 1)
+	}
+
+	_, err = client.JoinRealm(wampClient.realm, nil)
+	if err != nil {
+		client.Close()
+		return err
+	}
+
+	wampClient.mutex.Lock()
+	if err = wampClient.ctx.Err(); err != nil {
+		// Closed while connecting
+		wampClient.mutex.Unlock()
+		client.Close()
+		return err
+	}
+	wampClient.client = client
+	wampClient.mutex.Unlock()
+
+	if wampClient.reconnectPolicy != nil {
+		go wampClient.supervise(client)
+	}
+
+	return nil
+}
+
+// supervise waits for the connection loss and reconnects
+func (wampClient *WampClient) supervise(client *turnpike.Client) {
+	select {
+	case <-client.ReceiveDone:
+	case <-wampClient.ctx.Done():
+		return
+	}
+
+	if wampClient.ctx.Err() != nil {
+		return
+	}
+
+	if !wampClient.emit(ConnectionEvent{State: ConnectionStateDisconnected, Err: errors.New("connection to WAMP router lost")}) {
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := wampClient.reconnectPolicy.wait(wampClient.ctx, attempt); err != nil {
+			return
+		}
+
+		err := wampClient.connect()
+		if err == nil {
+			if wampClient.emit(ConnectionEvent{State: ConnectionStateConnected, Attempt: attempt}) {
+				topics, err := wampClient.resubscribe()
+				wampClient.emit(ConnectionEvent{State: ConnectionStateResubscribed, Attempt: attempt, Topics: topics, Err: err})
+			}
+			return
+		}
+
+		if wampClient.ctx.Err() != nil {
+			return
+		}
+
+		if wampClient.reconnectPolicy.MaxAttempts > 0 && attempt >= wampClient.reconnectPolicy.MaxAttempts {
+			wampClient.emit(ConnectionEvent{State: ConnectionStateDisconnected, Attempt: attempt,
+				Err: fmt.Errorf("giving up reconnecting after %d attempts: %s", attempt, err)})
+			return
+		}
+
+		if !wampClient.emit(ConnectionEvent{State: ConnectionStateDisconnected, Attempt: attempt, Err: err}) {
+			return
+		}
 	}
+}
+
+// resubscribe subscribes the current connection to every known topic and returns the subscribed ones
+func (wampClient *WampClient) resubscribe() (topics []string, err error) {
+	wampClient.mutex.Lock()
+	defer wampClient.mutex.Unlock()
 
-	return &WampClient{client: client}, nil
+	for topic, handler := range wampClient.subscriptions {
+		if subscribeErr := wampClient.client.Subscribe(topic, nil, handler); subscribeErr != nil {
+			err = fmt.Errorf("resubscribing to %s: %s", topic, subscribeErr)
+			continue
+		}
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	return topics, err
+}
+
+func (wampClient *WampClient) emit(event ConnectionEvent) bool {
+	if wampClient.events == nil {
+		return wampClient.ctx.Err() == nil
+	}
+
+	select {
+	case wampClient.events <- event:
+		return true
+	case <-wampClient.ctx.Done():
+		return false
+	}
 }
 
 func (wampClient *WampClient) Close() error {
+	wampClient.cancel()
+
+	wampClient.mutex.Lock()
+	defer wampClient.mutex.Unlock()
+
 	return wampClient.client.Close()
 }
 
 func (wampClient *WampClient) UnsubscribeFromPair(pair string) error {
+	wampClient.mutex.Lock()
+	defer wampClient.mutex.Unlock()
+
 	if err := wampClient.client.Unsubscribe(pair); err != nil {
 		return err
 	}
+	delete(wampClient.subscriptions, pair)
 
 	return nil
 }
 
+// SubscribeToPair sends OrderModification, NewTrade and SequenceGap messages of the pair to messageChan.
+// Sequences are kept across reconnections, so events lost meanwhile are reported as a gap.
+// The channels are sent to from the websocket reader, Subscribe offers typed buffered channels instead.
 func (wampClient *WampClient) SubscribeToPair(pair string, messageChan chan interface{}, errChan chan error) error {
-	if err := wampClient.client.Subscribe(pair, nil, marketMessageHandler(messageChan, errChan, pair)); err != nil {
+	return wampClient.subscribe(pair, marketMessageHandler(channelSink{messageChan, errChan}, pair, newSequenceTracker(pair, wampClient.reorderWindow, wampClient.reorderTimeout)))
+}
+
+// subscribe remembers the handler to subscribe it again after reconnection
+func (wampClient *WampClient) subscribe(topic string, handler turnpike.EventHandler) error {
+	wampClient.mutex.Lock()
+	defer wampClient.mutex.Unlock()
+
+	if err := wampClient.client.Subscribe(topic, nil, handler); err != nil {
 		return err
 	}
+	wampClient.subscriptions[topic] = handler
 
 	return nil
 }
 
-func marketMessageHandler(messageChan chan interface{}, errChan chan error, pair string) turnpike.EventHandler {
+// marketSink receives decoded events of a pair
+type marketSink interface {
+	orderModification(orderModification OrderModification)
+	newTrade(newTrade NewTrade)
+	sequenceGap(gap SequenceGap)
+	err(err error)
+}
+
+// channelSink sends every event to the channels of SubscribeToPair
+type channelSink struct {
+	messageChan chan interface{}
+	errChan     chan error
+}
+
+func (sink channelSink) orderModification(orderModification OrderModification) {
+	sink.messageChan <- orderModification
+}
+
+func (sink channelSink) newTrade(newTrade NewTrade) {
+	sink.messageChan <- newTrade
+}
+
+func (sink channelSink) sequenceGap(gap SequenceGap) {
+	sink.messageChan <- gap
+}
+
+func (sink channelSink) err(err error) {
+	sink.errChan <- err
+}
+
+// marketMessageHandler delivers events of the pair in sequence order, with SequenceGap messages reporting missing ones
+func marketMessageHandler(sink marketSink, pair string, tracker *sequenceTracker) turnpike.EventHandler {
+	deliver := func(events []sequencedEvent) {
+		for _, event := range events {
+			if event.gap != nil {
+				sink.sequenceGap(*event.gap)
+				continue
+			}
+
+			handleMarketEvent(sink, pair, event.sequence, event.args)
+		}
+	}
+
 	return func(args []interface{}, kwargs map[string]interface{}) {
 		sequence, err := parseSequence(kwargs)
 
 		if err != nil {
-			errChan <- err
+			sink.err(err)
 			return
 		}
 
-		for _, messageArg := range args {
-			message := &marketMessage{Sequence: sequence, Pair: pair}
-			if err := mapstructure.Decode(messageArg, &message); err != nil {
-				errChan <- err
-				continue
-			}
+		tracker.mutex.Lock()
+		defer tracker.mutex.Unlock()
 
-			if message.Type == messageTypeNewTrade {
-				newTrade, err := message.newTrade()
-				if err != nil {
-					errChan <- err
-					continue
-				}
+		deliver(tracker.push(sequence, args))
+		tracker.schedule(deliver)
+	}
+}
 
-				messageChan <- newTrade
-				continue
-			}
+func handleMarketEvent(sink marketSink, pair string, sequence uint, args []interface{}) {
+	for _, messageArg := range args {
+		message := &marketMessage{Sequence: sequence, Pair: pair}
+		if err := mapstructure.Decode(messageArg, &message); err != nil {
+			sink.err(err)
+			continue
+		}
 
-			orderModification, err := message.orderModification()
+		if message.Type == messageTypeNewTrade {
+			newTrade, err := message.newTrade()
 			if err != nil {
-				errChan <- err
+				sink.err(err)
 				continue
 			}
 
-			messageChan <- orderModification
+			sink.newTrade(newTrade)
+			continue
 		}
+
+		orderModification, err := message.orderModification()
+		if err != nil {
+			sink.err(err)
+			continue
+		}
+
+		sink.orderModification(orderModification)
 	}
 }
 
@@ -153,11 +446,15 @@ func (message marketMessage) orderModification() (orderModification OrderModific
 func (message marketMessage) newTrade() (newTrade NewTrade, err error) {
 	newTrade.Sequence = message.Sequence
 	newTrade.Trade = Trade{
-		Date:         message.Data.Date,
 		Type:         message.Data.Type,
 		CurrencyPair: message.Pair,
 	}
 
+	newTrade.Date, err = parseDate(message.Data.Date)
+	if err != nil {
+		return newTrade, fmt.Errorf("marketMessage.newTrade(), date: %s", err)
+	}
+
 	newTrade.Rate, err = decimal.NewFromString(message.Data.Rate)
 	if err != nil {
 		return newTrade, fmt.Errorf("marketMessage.newTrade(), rate: %s", err)
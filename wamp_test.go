@@ -7,7 +7,10 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/shopspring/decimal"
 	. "github.com/smartystreets/goconvey/convey"
@@ -160,6 +163,135 @@ func TestNewWampClient(t *testing.T) {
 	})
 }
 
+func TestWampClient_reconnect(t *testing.T) {
+	Convey("Given fake WAMP server", t, func() {
+		wampServer, httpServer, clear := newTestWebsocketServer(t)
+		defer clear()
+
+		localClient, err := wampServer.GetLocalClient(wampRealm, nil)
+		if err != nil {
+			t.Fatalf("error getting local client: %s", err)
+		}
+
+		var mutex sync.Mutex
+		var conns []net.Conn
+		events := make(chan ConnectionEvent, 10)
+
+		client, err := NewWampClient(&tls.Config{InsecureSkipVerify: true}, func(network, addr string) (net.Conn, error) {
+			conn, err := net.Dial(network, addr)
+			if err == nil {
+				mutex.Lock()
+				conns = append(conns, conn)
+				mutex.Unlock()
+			}
+			return conn, err
+		},
+			WithWampEndpoint(strings.Replace(httpServer.URL, "https://", "wss://", 1)),
+			WithWampReconnect(RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}),
+			WithWampConnectionEvents(events))
+		if err != nil {
+			t.Fatalf("error creating client: %s", err)
+		}
+		defer client.Close()
+
+		messageChan := make(chan interface{})
+		errChan := make(chan error)
+
+		if err := client.SubscribeToPair("BTC_ETH", messageChan, errChan); err != nil {
+			t.Fatalf("error subscribing to pair: %s", err)
+		}
+
+		Convey("It should resubscribe after connection loss", func() {
+			mutex.Lock()
+			conns[0].Close()
+			mutex.Unlock()
+
+			So((<-events).State, ShouldEqual, ConnectionStateDisconnected)
+			So((<-events).State, ShouldEqual, ConnectionStateConnected)
+
+			resubscribed := <-events
+			So(resubscribed.State, ShouldEqual, ConnectionStateResubscribed)
+			So(resubscribed.Topics, ShouldResemble, []string{"BTC_ETH"})
+			So(resubscribed.Err, ShouldBeNil)
+
+			modifyMarketMessage := map[string]interface{}{
+				"type": messageTypeOrderBookModify,
+				"data": map[string]interface{}{
+					"type":   "bid",
+					"rate":   "0.08529432",
+					"amount": "5.00000000",
+				},
+			}
+
+			args := []interface{}{interface{}(modifyMarketMessage)}
+			kwargs := map[string]interface{}{"seq": float64(1)}
+
+			if err := localClient.Publish("BTC_ETH", nil, args, kwargs); err != nil {
+				t.Fatalf("error publising to pair: %s", err)
+			}
+
+			_, ok := (<-messageChan).(OrderModification)
+			So(ok, ShouldBeTrue)
+		})
+	})
+}
+
+func Test_marketMessageHandler(t *testing.T) {
+	Convey("Given handler of pair", t, func() {
+		messageChan := make(chan interface{}, 10)
+		errChan := make(chan error, 10)
+		handler := marketMessageHandler(channelSink{messageChan, errChan}, "BTC_ETH", newSequenceTracker("BTC_ETH", 0, 0))
+
+		publish := func(sequence float64) {
+			handler([]interface{}{map[string]interface{}{
+				"type": messageTypeOrderBookRemove,
+				"data": map[string]interface{}{"type": "ask", "rate": "0.08529432"},
+			}}, map[string]interface{}{"seq": sequence})
+		}
+
+		Convey("It should report missing events before the following ones", func() {
+			publish(1)
+			publish(3)
+
+			So((<-messageChan).(OrderModification).Sequence, ShouldEqual, 1)
+			So(<-messageChan, ShouldResemble, SequenceGap{Pair: "BTC_ETH", Expected: 2, Got: 3})
+			So((<-messageChan).(OrderModification).Sequence, ShouldEqual, 3)
+			So(errChan, ShouldBeEmpty)
+		})
+
+		Convey("It should release held back events after the reorder timeout", func() {
+			handler = marketMessageHandler(channelSink{messageChan, errChan}, "BTC_ETH", newSequenceTracker("BTC_ETH", 3, 20*time.Millisecond))
+			publish(1)
+			publish(3)
+			publish(4)
+
+			So((<-messageChan).(OrderModification).Sequence, ShouldEqual, 1)
+			So(messageChan, ShouldBeEmpty)
+
+			So(<-messageChan, ShouldResemble, SequenceGap{Pair: "BTC_ETH", Expected: 2, Got: 3})
+			So((<-messageChan).(OrderModification).Sequence, ShouldEqual, 3)
+			So((<-messageChan).(OrderModification).Sequence, ShouldEqual, 4)
+
+			publish(5)
+			So((<-messageChan).(OrderModification).Sequence, ShouldEqual, 5)
+		})
+
+		Convey("It should not release events once the missing ones arrive", func() {
+			handler = marketMessageHandler(channelSink{messageChan, errChan}, "BTC_ETH", newSequenceTracker("BTC_ETH", 3, 20*time.Millisecond))
+			publish(1)
+			publish(3)
+			publish(2)
+
+			for _, sequence := range []uint{1, 2, 3} {
+				So((<-messageChan).(OrderModification).Sequence, ShouldEqual, sequence)
+			}
+
+			time.Sleep(40 * time.Millisecond)
+			So(messageChan, ShouldBeEmpty)
+		})
+	})
+}
+
 func newTestWebsocketServer(t *testing.T) (*turnpike.WebsocketServer, *httptest.Server, func()) {
 	wampServer := turnpike.NewBasicWebsocketServer(wampRealm)
 	httpServer := httptest.NewTLSServer(wampServer)
@@ -182,13 +314,32 @@ func Test_marketMessage_newTrade(t *testing.T) {
 		wantErr      bool
 	}{
 		{
-			name: "rate parse error",
+			name: "date parse error",
 			fields: fields{
 				Sequence: 1,
 				Pair:     "ETH_BTC",
 				Data: marketMessageData{
 					Date: "30.08.2017",
 					Type: TypeSell,
+				},
+			},
+			wantNewTrade: NewTrade{
+				Sequence: 1,
+				Trade: Trade{
+					CurrencyPair: "ETH_BTC",
+					Type:         TypeSell,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "rate parse error",
+			fields: fields{
+				Sequence: 1,
+				Pair:     "ETH_BTC",
+				Data: marketMessageData{
+					Date: "2017-08-30 00:00:00",
+					Type: TypeSell,
 					Rate: "invalid",
 				},
 			},
@@ -196,7 +347,7 @@ func Test_marketMessage_newTrade(t *testing.T) {
 				Sequence: 1,
 				Trade: Trade{
 					CurrencyPair: "ETH_BTC",
-					Date:         "30.08.2017",
+					Date:         time.Date(2017, 8, 30, 0, 0, 0, 0, time.UTC),
 					Type:         TypeSell,
 				},
 			},
@@ -208,7 +359,7 @@ func Test_marketMessage_newTrade(t *testing.T) {
 				Sequence: 1,
 				Pair:     "ETH_BTC",
 				Data: marketMessageData{
-					Date:   "30.08.2017",
+					Date:   "2017-08-30 00:00:00",
 					Type:   TypeSell,
 					Rate:   "0.1",
 					Amount: "invalid",
@@ -218,7 +369,7 @@ func Test_marketMessage_newTrade(t *testing.T) {
 				Sequence: 1,
 				Trade: Trade{
 					CurrencyPair: "ETH_BTC",
-					Date:         "30.08.2017",
+					Date:         time.Date(2017, 8, 30, 0, 0, 0, 0, time.UTC),
 					Type:         TypeSell,
 					Rate:         decimal.New(1, -1),
 				},
@@ -231,7 +382,7 @@ func Test_marketMessage_newTrade(t *testing.T) {
 				Sequence: 1,
 				Pair:     "ETH_BTC",
 				Data: marketMessageData{
-					Date:   "30.08.2017",
+					Date:   "2017-08-30 00:00:00",
 					Type:   TypeSell,
 					Rate:   "0.1",
 					Amount: "0.01",
@@ -242,7 +393,7 @@ func Test_marketMessage_newTrade(t *testing.T) {
 				Sequence: 1,
 				Trade: Trade{
 					CurrencyPair: "ETH_BTC",
-					Date:         "30.08.2017",
+					Date:         time.Date(2017, 8, 30, 0, 0, 0, 0, time.UTC),
 					Type:         TypeSell,
 					Rate:         decimal.New(1, -1),
 					Amount:       decimal.New(1, -2),
@@ -256,7 +407,7 @@ func Test_marketMessage_newTrade(t *testing.T) {
 				Sequence: 1,
 				Pair:     "ETH_BTC",
 				Data: marketMessageData{
-					Date:    "30.08.2017",
+					Date:    "2017-08-30 00:00:00",
 					Type:    TypeSell,
 					Rate:    "0.1",
 					Amount:  "0.01",
@@ -268,7 +419,7 @@ func Test_marketMessage_newTrade(t *testing.T) {
 				Sequence: 1,
 				Trade: Trade{
 					CurrencyPair: "ETH_BTC",
-					Date:         "30.08.2017",
+					Date:         time.Date(2017, 8, 30, 0, 0, 0, 0, time.UTC),
 					Type:         TypeSell,
 					Rate:         decimal.New(1, -1),
 					Amount:       decimal.New(1, -2),
@@ -0,0 +1,215 @@
+package poloniex
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultWatcherInterval = 30 * time.Second
+	defaultWatcherOverlap  = 10 * time.Minute
+)
+
+type DepositEventType int
+
+const (
+	// DepositAppeared is emitted once, when a deposit is seen for the first time
+	DepositAppeared DepositEventType = iota
+	DepositConfirmationsChanged
+	// DepositConfirmed is emitted once, when a deposit reaches the minimum confirmations of its currency or completes
+	DepositConfirmed
+)
+
+type DepositEvent struct {
+	Type        DepositEventType
+	Deposit     Deposit
+	MinimumConf uint
+}
+
+type watchedDeposit struct {
+	timestamp     time.Time
+	confirmations uint
+	confirmed     bool
+}
+
+// DepositWatcher polls returnDepositsWithdrawals and reports every deposit change exactly once.
+// The polled period starts at the oldest unconfirmed deposit, or at the latest seen one,
+// so deposits are followed until they are confirmed.
+type DepositWatcher struct {
+	client *Client
+
+	Interval time.Duration
+	// Overlap widens the polled period to tolerate clock skew with the exchange
+	Overlap time.Duration
+
+	since time.Time
+	start time.Time
+	// forgotten never moves backwards, confirmed deposits before it are forgotten and skipped even when start moves back
+	forgotten  time.Time
+	deposits   map[uint]*watchedDeposit
+	currencies map[string]Currency
+}
+
+// NewDepositWatcher creates a watcher reporting deposits made since the given time
+func NewDepositWatcher(client *Client, since time.Time) *DepositWatcher {
+	return &DepositWatcher{
+		client:   client,
+		Interval: defaultWatcherInterval,
+		Overlap:  defaultWatcherOverlap,
+		since:    since,
+		start:    since,
+		deposits: make(map[uint]*watchedDeposit),
+	}
+}
+
+// Run polls until ctx is done and returns ctx.Err(). Failed polls are reported to errChan, which may be nil,
+// and retried on the next tick.
+func (watcher *DepositWatcher) Run(ctx context.Context, events chan<- DepositEvent, errChan chan<- error) error {
+	ticker := time.NewTicker(watcher.Interval)
+	defer ticker.Stop()
+
+	for {
+		polledEvents, err := watcher.Poll(ctx)
+		if err != nil && errChan != nil && ctx.Err() == nil {
+			select {
+			case errChan <- err:
+			case <-ctx.Done():
+			}
+		}
+
+		for _, event := range polledEvents {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Poll fetches deposits once and returns the events since the previous poll.
+// Deposits of currencies missing from returnCurrencies are never confirmed, they are reported in err along with the events.
+func (watcher *DepositWatcher) Poll(ctx context.Context) (events []DepositEvent, err error) {
+	from := watcher.start
+	if !from.IsZero() {
+		from = from.Add(-watcher.Overlap)
+	}
+
+	response, err := watcher.client.DepositsWithdrawalsBetweenCtx(ctx, from, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	deposits := response.Deposits
+	sort.Slice(deposits, func(i, j int) bool {
+		return deposits[i].DepositNumber < deposits[j].DepositNumber
+	})
+
+	if err = watcher.loadCurrencies(ctx, deposits); err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	for _, deposit := range deposits {
+		// Confirmed deposits before the polled period are forgotten and must not be reported again
+		if deposit.Timestamp.Before(watcher.since) || deposit.Timestamp.Before(from) {
+			continue
+		}
+
+		state, seen := watcher.deposits[deposit.DepositNumber]
+		if !seen && deposit.Timestamp.Before(watcher.forgotten) {
+			continue
+		}
+
+		currency, known := watcher.currencies[deposit.Currency]
+		minimumConf := currency.MinimumConf
+		if !known {
+			unknown = append(unknown, fmt.Sprintf("%d (%s)", deposit.DepositNumber, deposit.Currency))
+		}
+
+		if !seen {
+			state = &watchedDeposit{timestamp: deposit.Timestamp, confirmations: deposit.Confirmations}
+			watcher.deposits[deposit.DepositNumber] = state
+			events = append(events, DepositEvent{Type: DepositAppeared, Deposit: *deposit, MinimumConf: minimumConf})
+		} else if state.confirmations != deposit.Confirmations {
+			state.confirmations = deposit.Confirmations
+			events = append(events, DepositEvent{Type: DepositConfirmationsChanged, Deposit: *deposit, MinimumConf: minimumConf})
+		}
+
+		if known && !state.confirmed && (deposit.Confirmations >= minimumConf || deposit.Status == TransferStatusComplete) {
+			state.confirmed = true
+			events = append(events, DepositEvent{Type: DepositConfirmed, Deposit: *deposit, MinimumConf: minimumConf})
+		}
+	}
+
+	watcher.advance()
+
+	if len(unknown) > 0 {
+		return events, fmt.Errorf("deposits of unknown currencies are held unconfirmed: %s", strings.Join(unknown, ", "))
+	}
+
+	return events, nil
+}
+
+// advance moves the polled period start to the oldest unconfirmed deposit
+// and forgets confirmed deposits which can not be polled anymore.
+// A new unconfirmed deposit may move the start back, the forgetting cutoff stays where it was.
+func (watcher *DepositWatcher) advance() {
+	var oldestPending, latest time.Time
+	for _, state := range watcher.deposits {
+		if !state.confirmed && (oldestPending.IsZero() || state.timestamp.Before(oldestPending)) {
+			oldestPending = state.timestamp
+		}
+		if state.timestamp.After(latest) {
+			latest = state.timestamp
+		}
+	}
+
+	switch {
+	case !oldestPending.IsZero():
+		watcher.start = oldestPending
+	case latest.After(watcher.start):
+		watcher.start = latest
+	}
+
+	if cutoff := watcher.start.Add(-watcher.Overlap); cutoff.After(watcher.forgotten) {
+		watcher.forgotten = cutoff
+	}
+
+	for number, state := range watcher.deposits {
+		if state.confirmed && state.timestamp.Before(watcher.forgotten) {
+			delete(watcher.deposits, number)
+		}
+	}
+}
+
+// loadCurrencies fetches currencies on first use and whenever a deposit of an unknown currency shows up
+func (watcher *DepositWatcher) loadCurrencies(ctx context.Context, deposits []*Deposit) error {
+	stale := watcher.currencies == nil
+	for _, deposit := range deposits {
+		if _, ok := watcher.currencies[deposit.Currency]; !ok {
+			stale = true
+		}
+	}
+
+	if !stale {
+		return nil
+	}
+
+	currencies, err := watcher.client.CurrenciesCtx(ctx)
+	if err != nil {
+		return err
+	}
+
+	watcher.currencies = currencies
+	return nil
+}
@@ -0,0 +1,160 @@
+package poloniex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDepositWatcher_Poll(t *testing.T) {
+	Convey("Setup correct server", t, func() {
+		const oldDeposit = `{"depositNumber":1,"currency":"BTC","address":"xyz","amount":"1.00000000","confirmations":5,"txid":"aaa","timestamp":1506000000,"status":"COMPLETE"}`
+		deposits := [][]string{
+			{oldDeposit, `{"depositNumber":2,"currency":"BTC","address":"xyz","amount":"0.50000000","confirmations":0,"txid":"bbb","timestamp":1506010000,"status":"PENDING"}`},
+			{oldDeposit, `{"depositNumber":2,"currency":"BTC","address":"xyz","amount":"0.50000000","confirmations":1,"txid":"bbb","timestamp":1506010000,"status":"PENDING"}`},
+			{oldDeposit, `{"depositNumber":2,"currency":"BTC","address":"xyz","amount":"0.50000000","confirmations":1,"txid":"bbb","timestamp":1506010000,"status":"PENDING"}`},
+			{oldDeposit, `{"depositNumber":2,"currency":"BTC","address":"xyz","amount":"0.50000000","confirmations":3,"txid":"bbb","timestamp":1506010000,"status":"PENDING"}`,
+				`{"depositNumber":3,"currency":"BTC","address":"xyz","amount":"0.10000000","confirmations":4,"txid":"ccc","timestamp":1506020000,"status":"COMPLETE"}`},
+			{`{"depositNumber":2,"currency":"BTC","address":"xyz","amount":"0.50000000","confirmations":4,"txid":"bbb","timestamp":1506010000,"status":"COMPLETE"}`,
+				`{"depositNumber":3,"currency":"BTC","address":"xyz","amount":"0.10000000","confirmations":4,"txid":"ccc","timestamp":1506020000,"status":"COMPLETE"}`},
+		}
+		polls := 0
+		var starts []string
+
+		handler := &fakeHandler{
+			HandleFunc: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("command") == "returnCurrencies" {
+					fmt.Fprint(w, `{"BTC":{"id":28,"name":"Bitcoin","txFee":"0.00050000","minConf":3,"depositAddress":null,"disabled":0,"delisted":0,"frozen":0}}`)
+					return
+				}
+
+				r.ParseForm()
+				starts = append(starts, r.PostForm.Get("start"))
+				fmt.Fprintf(w, `{"deposits":[%s],"withdrawals":[]}`, strings.Join(deposits[polls], ","))
+				if polls < len(deposits)-1 {
+					polls++
+				}
+			},
+		}
+		server := createFakeServer(handler)
+		defer server.Close()
+
+		client := NewClient([]Key{Key{"key", "secret"}})
+		client.SetTransport(transportForTesting(server))
+		client.SetRequestRateLimit(1000)
+
+		watcher := NewDepositWatcher(client, time.Unix(1506005000, 0))
+		watcher.Overlap = time.Second
+
+		Convey("Should report every change once", func() {
+			type event struct {
+				Type          DepositEventType
+				DepositNumber uint
+				Confirmations uint
+			}
+
+			var received [][]event
+			for range deposits {
+				events, err := watcher.Poll(context.Background())
+				So(err, ShouldBeNil)
+
+				var pollEvents []event
+				for _, e := range events {
+					So(e.MinimumConf, ShouldEqual, 3)
+					pollEvents = append(pollEvents, event{e.Type, e.Deposit.DepositNumber, e.Deposit.Confirmations})
+				}
+				received = append(received, pollEvents)
+			}
+
+			So(received, ShouldResemble, [][]event{
+				{{DepositAppeared, 2, 0}},
+				{{DepositConfirmationsChanged, 2, 1}},
+				nil,
+				{{DepositConfirmationsChanged, 2, 3}, {DepositConfirmed, 2, 3}, {DepositAppeared, 3, 4}, {DepositConfirmed, 3, 4}},
+				nil,
+			})
+		})
+
+		Convey("Should hold deposits of unknown currencies unconfirmed", func() {
+			deposits = [][]string{{`{"depositNumber":4,"currency":"NEWCOIN","address":"xyz","amount":"1.00000000","confirmations":0,"txid":"ddd","timestamp":1506010000,"status":"COMPLETE"}`}}
+
+			events, err := watcher.Poll(context.Background())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "4 (NEWCOIN)")
+			So(events, ShouldHaveLength, 1)
+			So(events[0].Type, ShouldEqual, DepositAppeared)
+
+			events, err = watcher.Poll(context.Background())
+			So(err, ShouldNotBeNil)
+			So(events, ShouldBeEmpty)
+		})
+
+		Convey("Should not report forgotten deposits again when the polled period moves back", func() {
+			const (
+				forgotten = `{"depositNumber":5,"currency":"BTC","address":"xyz","amount":"1.00000000","confirmations":5,"txid":"eee","timestamp":1506019995,"status":"COMPLETE"}`
+				latest    = `{"depositNumber":6,"currency":"BTC","address":"xyz","amount":"1.00000000","confirmations":5,"txid":"fff","timestamp":1506020010,"status":"COMPLETE"}`
+				late      = `{"depositNumber":7,"currency":"BTC","address":"xyz","amount":"1.00000000","confirmations":0,"txid":"ggg","timestamp":1506020001,"status":"PENDING"}`
+			)
+			deposits = [][]string{{forgotten, latest}, {forgotten, latest, late}, {forgotten, latest, late}}
+			watcher.Overlap = 10 * time.Second
+
+			var received [][]uint
+			for range deposits {
+				events, err := watcher.Poll(context.Background())
+				So(err, ShouldBeNil)
+
+				var numbers []uint
+				for _, e := range events {
+					numbers = append(numbers, e.Deposit.DepositNumber)
+				}
+				received = append(received, numbers)
+			}
+
+			So(starts, ShouldResemble, []string{"1506004990", "1506020000", "1506019991"})
+			So(received, ShouldResemble, [][]uint{{5, 5, 6, 6}, {7}, nil})
+		})
+
+		Convey("Should keep polling without error channel", func() {
+			const unknown = `{"depositNumber":4,"currency":"NEWCOIN","address":"xyz","amount":"1.00000000","confirmations":0,"txid":"ddd","timestamp":1506010000,"status":"PENDING"}`
+			deposits = [][]string{{unknown}, {unknown, `{"depositNumber":8,"currency":"BTC","address":"xyz","amount":"1.00000000","confirmations":5,"txid":"hhh","timestamp":1506010001,"status":"COMPLETE"}`}}
+			watcher.Interval = time.Millisecond
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			events := make(chan DepositEvent)
+			done := make(chan error, 1)
+			go func() {
+				done <- watcher.Run(ctx, events, nil)
+			}()
+
+			var received []DepositEventType
+			for len(received) < 3 {
+				select {
+				case event := <-events:
+					received = append(received, event.Type)
+				case <-ctx.Done():
+					t.Fatal("watcher stopped delivering events")
+				}
+			}
+			cancel()
+
+			So(received, ShouldResemble, []DepositEventType{DepositAppeared, DepositAppeared, DepositConfirmed})
+			So(<-done, ShouldEqual, context.Canceled)
+		})
+
+		Convey("Should poll from the oldest unconfirmed deposit", func() {
+			for range deposits {
+				_, err := watcher.Poll(context.Background())
+				So(err, ShouldBeNil)
+			}
+
+			So(starts, ShouldResemble, []string{"1506004999", "1506009999", "1506009999", "1506009999", "1506019999"})
+		})
+	})
+}